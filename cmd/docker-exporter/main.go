@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -31,54 +32,88 @@ func main() {
 
 	logger.Info("Starting Docker Exporter",
 		zap.String("version", config.Version),
-		zap.String("docker_host", cfg.DockerHost),
+		zap.Int("docker_daemons", len(cfg.DockerDaemons())),
 		zap.String("address", cfg.Address()),
 		zap.String("metrics_path", cfg.MetricsPath()),
 	)
 
-	// Create Docker client
-	dockerClient, err := docker.NewClient(cfg.DockerHost)
+	if cfg.DiskUsageInterval == 0 && cfg.CollectorEnabled("volume") {
+		logger.Info("Disk usage collection disabled, volume sizes are not exported (see --disk-usage-interval)")
+	}
+
+	// Container filter
+	filter, err := docker.NewFilter(cfg.Include, cfg.Exclude)
 	if err != nil {
-		logger.Fatal("Failed to create Docker client", zap.Error(err))
+		logger.Fatal("Invalid container filter", zap.Error(err))
 	}
-	defer dockerClient.Close()
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	if err := dockerClient.Ping(ctx); err != nil {
-		logger.Fatal("Failed to connect to Docker daemon",
-			zap.String("host", cfg.DockerHost),
-			zap.Error(err))
+	// One client and collector per Docker daemon
+	var daemons []*daemon
+	for _, d := range cfg.DockerDaemons() {
+		dm, err := newDaemon(cfg, d, filter, logger)
+		if err != nil {
+			logger.Fatal("Failed to create Docker client",
+				zap.String("docker_host", d.Name),
+				zap.Error(err))
+		}
+		defer dm.client.Close()
+		daemons = append(daemons, dm)
 	}
-	cancel()
-	logger.Info("Connected to Docker daemon", zap.String("host", cfg.DockerHost))
+	checkDaemons(cfg, daemons, logger)
 
-	// Create collector
-	coll := collector.NewCollector(dockerClient, cfg, logger)
+	// Background collection and events watch run until shutdown
+	runCtx, stopRun := context.WithCancel(context.Background())
+	defer stopRun()
+	for _, dm := range daemons {
+		go dm.collector.Run(runCtx)
+	}
 
 	// Setup metrics handler based on output mode
-	var metricsHandler http.Handler
+	var (
+		registerer     prometheus.Registerer
+		metricsHandler http.Handler
+	)
 	if cfg.OutputMode == "minimum" {
 		// Use custom registry with only our collector (no go_*, process_*, promhttp_*)
 		registry := prometheus.NewRegistry()
-		registry.MustRegister(coll)
+		registerer = registry
 		metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{
 			EnableOpenMetrics: true,
 		})
 		logger.Info("Output mode: minimum (only ndocker_* metrics)")
 	} else {
 		// Use default registry (includes go_*, process_*, promhttp_*)
-		prometheus.MustRegister(coll)
+		registerer = prometheus.DefaultRegisterer
 		metricsHandler = promhttp.Handler()
 		logger.Info("Output mode: all (includes go_*, process_*, promhttp_* metrics)")
 	}
 
+	// With several daemons every series carries the daemon name
+	for _, dm := range daemons {
+		r := registerer
+		if cfg.MultiDaemon() {
+			r = prometheus.WrapRegistererWith(prometheus.Labels{"docker_host": dm.name}, registerer)
+		}
+		r.MustRegister(dm.collector)
+	}
+
 	// Setup HTTP server
 	mux := http.NewServeMux()
 
 	// Metrics endpoint
 	mux.Handle(cfg.MetricsPath(), metricsHandler)
 
+	// Probe endpoint for targets chosen by Prometheus service discovery
+	if cfg.Probe {
+		targets, err := probeTargets(cfg)
+		if err != nil {
+			logger.Fatal("Invalid probe target", zap.Error(err))
+		}
+		pool := newClientPool(cfg, filter, logger)
+		defer pool.Close()
+		mux.Handle("/probe", probeHandler(cfg, targets, pool))
+	}
+
 	// Root endpoint
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -93,17 +128,30 @@ func main() {
 </html>`, config.Version, cfg.MetricsPath())
 	})
 
-	// Health endpoint
+	// Health endpoint, healthy while at least one daemon answers
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 		defer cancel()
-		if err := dockerClient.Ping(ctx); err != nil {
+
+		errs := pingDaemons(ctx, daemons)
+		if len(errs) == len(daemons) {
 			w.WriteHeader(http.StatusServiceUnavailable)
-			fmt.Fprintf(w, "unhealthy: %v", err)
-			return
+			fmt.Fprint(w, "unhealthy")
+		} else {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, "healthy")
+		}
+		if cfg.MultiDaemon() {
+			for _, dm := range daemons {
+				if err, failed := errs[dm.name]; failed {
+					fmt.Fprintf(w, "\n%s: %v", dm.name, err)
+				} else {
+					fmt.Fprintf(w, "\n%s: ok", dm.name)
+				}
+			}
+		} else if len(errs) > 0 {
+			fmt.Fprintf(w, ": %v", errs[daemons[0].name])
 		}
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprint(w, "healthy")
 	})
 
 	server := &http.Server{
@@ -121,6 +169,7 @@ func main() {
 		<-sigChan
 
 		logger.Info("Shutting down server...")
+		stopRun()
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		server.Shutdown(ctx)
@@ -135,6 +184,111 @@ func main() {
 	logger.Info("Server stopped")
 }
 
+// daemon is one Docker daemon with its client and collector
+type daemon struct {
+	name      string
+	client    *docker.Client
+	collector *collector.Collector
+}
+
+// cgroupRoot returns the cgroup filesystem to read container stats from, or "" to
+// read them from the Docker API
+func cgroupRoot(cfg *config.Config) string {
+	if cfg.StatsSource == "cgroup" {
+		return cfg.CgroupRoot
+	}
+	return ""
+}
+
+// newDaemon creates the client and collector of a Docker daemon
+func newDaemon(cfg *config.Config, d config.DaemonConfig, filter *docker.Filter, logger *zap.Logger) (*daemon, error) {
+	client, err := docker.NewClient(docker.Options{
+		Host:   d.Host,
+		Filter: filter,
+		TLS: docker.TLSOptions{
+			CAFile:   d.TLSCA,
+			CertFile: d.TLSCert,
+			KeyFile:  d.TLSKey,
+			Verify:   d.TLSVerify,
+		},
+		InspectConcurrency: cfg.InspectConcurrency,
+		SkipInspect:        !cfg.ContainerInspect,
+		CgroupRoot:         cgroupRoot(cfg),
+		ProcRoot:           cfg.ProcRoot,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MultiDaemon() {
+		logger = logger.With(zap.String("docker_host", d.Name))
+	}
+
+	// Collector settings that differ per daemon
+	daemonCfg := *cfg
+	daemonCfg.Timeout = d.Timeout
+
+	return &daemon{
+		name:      d.Name,
+		client:    client,
+		collector: collector.NewCollector(client, &daemonCfg, logger),
+	}, nil
+}
+
+// checkDaemons tests the connection to every daemon, concurrently so that unreachable
+// daemons do not delay startup one after another. An unreachable daemon is fatal when
+// it is the only one; with several daemons it is logged and reported by its up metric
+// so the others keep working.
+func checkDaemons(cfg *config.Config, daemons []*daemon, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	errs := pingDaemons(ctx, daemons)
+
+	for _, d := range cfg.DockerDaemons() {
+		log := logger
+		if cfg.MultiDaemon() {
+			log = logger.With(zap.String("docker_host", d.Name))
+		}
+		err, failed := errs[d.Name]
+		switch {
+		case !failed:
+			log.Info("Connected to Docker daemon",
+				zap.String("host", d.Host),
+				zap.Bool("docker_tls", d.TLSEnabled()))
+		case !cfg.MultiDaemon():
+			log.Fatal("Failed to connect to Docker daemon",
+				zap.String("host", d.Host),
+				zap.Error(err))
+		default:
+			log.Warn("Failed to connect to Docker daemon, collecting anyway",
+				zap.String("host", d.Host),
+				zap.Error(err))
+		}
+	}
+}
+
+// pingDaemons pings all daemons concurrently and returns the errors by daemon name
+func pingDaemons(ctx context.Context, daemons []*daemon) map[string]error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(map[string]error)
+	)
+	for _, dm := range daemons {
+		wg.Add(1)
+		go func(dm *daemon) {
+			defer wg.Done()
+			if err := dm.client.Ping(ctx); err != nil {
+				mu.Lock()
+				errs[dm.name] = err
+				mu.Unlock()
+			}
+		}(dm)
+	}
+	wg.Wait()
+	return errs
+}
+
 // setupLogger creates a zap logger
 func setupLogger(level, path string) *zap.Logger {
 	// Parse log level
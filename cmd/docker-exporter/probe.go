@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/nhattuanbl/docker-exporter/internal/collector"
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const (
+	// probeClientIdle is how long an unused /probe client is kept in the pool
+	probeClientIdle = 10 * time.Minute
+
+	// probeMaxClients caps the pool; the least recently used client makes room for a new one
+	probeMaxClients = 64
+)
+
+// pooledClient is a Docker client and its collector, kept between probes of the same
+// target so that the collector's counters and last-known stats carry over
+type pooledClient struct {
+	client    *docker.Client
+	collector *collector.Collector
+	lastUsed  time.Time
+}
+
+// clientPool reuses Docker clients, their connections and collectors across probes.
+// Clients are keyed by module and target since the module decides the TLS settings
+// and collectors.
+type clientPool struct {
+	cfg    *config.Config
+	filter *docker.Filter
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+}
+
+// newClientPool creates an empty clientPool
+func newClientPool(cfg *config.Config, filter *docker.Filter, logger *zap.Logger) *clientPool {
+	return &clientPool{
+		cfg:     cfg,
+		filter:  filter,
+		logger:  logger,
+		clients: make(map[string]*pooledClient),
+	}
+}
+
+// Get returns the collector of a target, creating its client on first use.
+// Clients idle for longer than probeClientIdle are closed on the way, and the least
+// recently used one when the pool is full.
+func (p *clientPool) Get(target, moduleName string, module config.ModuleConfig) (*collector.Collector, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for key, pc := range p.clients {
+		if now.Sub(pc.lastUsed) > probeClientIdle {
+			pc.client.Close()
+			delete(p.clients, key)
+		}
+	}
+
+	key := moduleName + "|" + target
+	if pc, ok := p.clients[key]; ok {
+		pc.lastUsed = now
+		return pc.collector, nil
+	}
+
+	if len(p.clients) >= probeMaxClients {
+		p.evictOldest()
+	}
+
+	client, err := docker.NewClient(docker.Options{
+		Host:   target,
+		Filter: p.filter,
+		TLS: docker.TLSOptions{
+			CAFile:   module.TLSCA,
+			CertFile: module.TLSCert,
+			KeyFile:  module.TLSKey,
+			Verify:   module.TLSVerify,
+		},
+		InspectConcurrency: p.cfg.InspectConcurrency,
+		SkipInspect:        !p.cfg.ContainerInspect,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Probes collect on request, without background collection, events or disk usage
+	probeCfg := *p.cfg
+	probeCfg.Timeout = module.Timeout
+	probeCfg.Collectors = module.Collectors
+	probeCfg.CollectInterval = 0
+	probeCfg.Events = false
+	probeCfg.DiskUsageInterval = 0
+
+	coll := collector.NewCollector(client, &probeCfg, p.logger.With(
+		zap.String("target", target),
+		zap.String("module", moduleName),
+	))
+	p.clients[key] = &pooledClient{client: client, collector: coll, lastUsed: now}
+
+	return coll, nil
+}
+
+// evictOldest closes the least recently used client. Callers must hold p.mu.
+func (p *clientPool) evictOldest() {
+	var (
+		oldestKey string
+		oldest    *pooledClient
+	)
+	for key, pc := range p.clients {
+		if oldest == nil || pc.lastUsed.Before(oldest.lastUsed) {
+			oldestKey, oldest = key, pc
+		}
+	}
+	if oldest != nil {
+		oldest.client.Close()
+		delete(p.clients, oldestKey)
+	}
+}
+
+// Close closes all pooled clients
+func (p *clientPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, pc := range p.clients {
+		pc.client.Close()
+		delete(p.clients, key)
+	}
+}
+
+// probeTargets compiles the target patterns of every /probe module, the implicit
+// default module included
+func probeTargets(cfg *config.Config) (map[string][]*regexp.Regexp, error) {
+	names := []string{"default"}
+	for name := range cfg.Modules {
+		names = append(names, name)
+	}
+
+	targets := make(map[string][]*regexp.Regexp, len(names))
+	for _, name := range names {
+		module, _ := cfg.Module(name)
+		for _, expr := range module.Targets {
+			re, err := config.TargetPattern(expr)
+			if err != nil {
+				return nil, fmt.Errorf("module %q: %w", name, err)
+			}
+			targets[name] = append(targets[name], re)
+		}
+	}
+	return targets, nil
+}
+
+// probeHandler serves /probe?target=<docker host>&module=<name>. Only targets matching
+// one of the module's target patterns are probed. Each module and target keeps its
+// collector in the pool, so counters survive between probes until the client is
+// evicted. Collectors run in scrape mode, so background collection, the events
+// inventory and disk usage data are not available.
+func probeHandler(cfg *config.Config, targets map[string][]*regexp.Regexp, pool *clientPool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		target := params.Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is missing", http.StatusBadRequest)
+			return
+		}
+
+		moduleName := params.Get("module")
+		if moduleName == "" {
+			moduleName = "default"
+		}
+		module, ok := cfg.Module(moduleName)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown module %q", moduleName), http.StatusBadRequest)
+			return
+		}
+
+		if !targetAllowed(targets[moduleName], target) {
+			http.Error(w, fmt.Sprintf("target %q is not allowed by module %q", target, moduleName), http.StatusForbidden)
+			return
+		}
+
+		coll, err := pool.Get(target, moduleName, module)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid target %q: %v", target, err), http.StatusBadRequest)
+			return
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(coll)
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	}
+}
+
+// targetAllowed reports whether target matches one of the patterns
+func targetAllowed(patterns []*regexp.Regexp, target string) bool {
+	for _, re := range patterns {
+		if re.MatchString(target) {
+			return true
+		}
+	}
+	return false
+}
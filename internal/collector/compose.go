@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Labels set by Docker Compose on the containers it creates
+const (
+	composeProjectLabel         = "com.docker.compose.project"
+	composeServiceLabel         = "com.docker.compose.service"
+	composeContainerNumberLabel = "com.docker.compose.container-number"
+	composeConfigHashLabel      = "com.docker.compose.config-hash"
+	composeOneoffLabel          = "com.docker.compose.oneoff"
+)
+
+// composeService identifies a Compose service
+type composeService struct {
+	project string
+	service string
+}
+
+// composeServiceState aggregates the containers of a Compose service
+type composeServiceState struct {
+	running int
+	hashes  map[string]struct{} // config hashes of the running containers
+}
+
+// collectComposeMetrics emits the Compose info metric per container and the per-service
+// rollups. Containers without the Compose project and service labels are skipped.
+func (c *containerCollector) collectComposeMetrics(containers []docker.ContainerInfo, ch chan<- prometheus.Metric) {
+	services := make(map[composeService]*composeServiceState)
+
+	for _, cont := range containers {
+		project, service := cont.Labels[composeProjectLabel], cont.Labels[composeServiceLabel]
+		if project == "" || service == "" {
+			continue
+		}
+		hash := cont.Labels[composeConfigHashLabel]
+
+		ch <- prometheus.MustNewConstMetric(
+			c.containerComposeInfo, prometheus.GaugeValue, 1,
+			cont.ID, cont.Name, project, service, cont.Labels[composeContainerNumberLabel], hash,
+		)
+
+		// Containers started by `docker compose run` are not replicas of the service
+		if cont.Labels[composeOneoffLabel] == "True" {
+			continue
+		}
+
+		key := composeService{project: project, service: service}
+		state := services[key]
+		if state == nil {
+			state = &composeServiceState{hashes: make(map[string]struct{})}
+			services[key] = state
+		}
+		// Stopped containers left over from an older config are not drift
+		if !cont.Running {
+			continue
+		}
+		state.running++
+		if hash != "" {
+			state.hashes[hash] = struct{}{}
+		}
+	}
+
+	keys := make([]composeService, 0, len(services))
+	for key := range services {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].project != keys[j].project {
+			return keys[i].project < keys[j].project
+		}
+		return keys[i].service < keys[j].service
+	})
+
+	for _, key := range keys {
+		state := services[key]
+
+		ch <- prometheus.MustNewConstMetric(
+			c.composeServiceRunning, prometheus.GaugeValue, float64(state.running),
+			key.project, key.service,
+		)
+
+		// Containers created from different configurations mean a partial recreate
+		var drift float64
+		if len(state.hashes) > 1 {
+			drift = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.composeServiceDrift, prometheus.GaugeValue, drift,
+			key.project, key.service,
+		)
+	}
+}
@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerSubCollector("container", true, func(c *Collector, cfg *config.Config) subCollector {
+		return newContainerCollector(c.prefix, c.labels)
+	})
+}
+
+// containerCollector exports the container state, labels, network attachments and
+// Compose rollups from the container list, without API calls of its own
+type containerCollector struct {
+	prefix string
+	labels *labelMatcher
+
+	containerInfo         *prometheus.Desc
+	containerState        *prometheus.Desc
+	containerUptime       *prometheus.Desc
+	containerCreated      *prometheus.Desc
+	containerStarted      *prometheus.Desc
+	containerRestartCount *prometheus.Desc
+	containerHealthStatus *prometheus.Desc
+	containerExitCode     *prometheus.Desc
+	containerOOMKilled    *prometheus.Desc
+	containerLabels       *prometheus.Desc
+	containerNetworkInfo  *prometheus.Desc
+
+	// Compose metrics
+	containerComposeInfo  *prometheus.Desc
+	composeServiceRunning *prometheus.Desc
+	composeServiceDrift   *prometheus.Desc
+}
+
+// newContainerCollector creates a new containerCollector
+func newContainerCollector(prefix string, labels *labelMatcher) *containerCollector {
+	return &containerCollector{
+		prefix: prefix,
+		labels: labels,
+
+		containerInfo: prometheus.NewDesc(
+			prefix+"_container_info",
+			"Container information",
+			[]string{"id", "name", "image", "state"}, nil,
+		),
+		containerState: prometheus.NewDesc(
+			prefix+"_container_state",
+			"Container state (1=running, 2=paused, 3=restarting, 4=exited, 5=dead, 6=created)",
+			[]string{"id", "name"}, nil,
+		),
+		containerUptime: prometheus.NewDesc(
+			prefix+"_container_uptime_seconds",
+			"Container uptime in seconds",
+			[]string{"id", "name"}, nil,
+		),
+		containerCreated: prometheus.NewDesc(
+			prefix+"_container_created_seconds",
+			"Container creation timestamp",
+			[]string{"id", "name"}, nil,
+		),
+		containerStarted: prometheus.NewDesc(
+			prefix+"_container_started_seconds",
+			"Container start timestamp",
+			[]string{"id", "name"}, nil,
+		),
+		containerRestartCount: prometheus.NewDesc(
+			prefix+"_container_restart_count",
+			"Container restart count",
+			[]string{"id", "name"}, nil,
+		),
+		containerHealthStatus: prometheus.NewDesc(
+			prefix+"_container_health_status",
+			"Container health status (1=healthy, 0=unhealthy, -1=none)",
+			[]string{"id", "name"}, nil,
+		),
+		containerExitCode: prometheus.NewDesc(
+			prefix+"_container_exit_code",
+			"Container exit code",
+			[]string{"id", "name"}, nil,
+		),
+		containerOOMKilled: prometheus.NewDesc(
+			prefix+"_container_oom_killed",
+			"Container OOM killed (1=true, 0=false)",
+			[]string{"id", "name"}, nil,
+		),
+		// Label names depend on the containers seen at scrape time, see collectContainerLabels
+		containerLabels: prometheus.NewDesc(
+			prefix+"_container_labels",
+			"Container labels selected by --container-label",
+			[]string{"id", "name"}, nil,
+		),
+		containerNetworkInfo: prometheus.NewDesc(
+			prefix+"_container_network_info",
+			"Container network attachment with its IP address",
+			[]string{"id", "name", "network", "ip_address"}, nil,
+		),
+
+		// Compose metrics
+		containerComposeInfo: prometheus.NewDesc(
+			prefix+"_container_compose_info",
+			"Docker Compose project and service of the container",
+			[]string{"id", "name", "project", "service", "container_number", "config_hash"}, nil,
+		),
+		composeServiceRunning: prometheus.NewDesc(
+			prefix+"_compose_service_replicas_running",
+			"Number of running containers of the Compose service",
+			[]string{"project", "service"}, nil,
+		),
+		composeServiceDrift: prometheus.NewDesc(
+			prefix+"_compose_service_config_drift",
+			"Whether the running containers of the Compose service have different config hashes (1=drift, 0=consistent)",
+			[]string{"project", "service"}, nil,
+		),
+	}
+}
+
+// Name implements subCollector
+func (c *containerCollector) Name() string {
+	return "container"
+}
+
+// Describe implements subCollector
+func (c *containerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.containerInfo
+	ch <- c.containerState
+	ch <- c.containerUptime
+	ch <- c.containerCreated
+	ch <- c.containerStarted
+	ch <- c.containerRestartCount
+	ch <- c.containerHealthStatus
+	ch <- c.containerExitCode
+	ch <- c.containerOOMKilled
+	ch <- c.containerLabels
+	ch <- c.containerNetworkInfo
+	ch <- c.containerComposeInfo
+	ch <- c.composeServiceRunning
+	ch <- c.composeServiceDrift
+}
+
+// Update implements subCollector
+func (c *containerCollector) Update(ctx context.Context, containers []docker.ContainerInfo, ch chan<- prometheus.Metric) error {
+	// Selected Docker labels
+	c.collectContainerLabels(containers, ch)
+
+	// Compose project and service rollups
+	c.collectComposeMetrics(containers, ch)
+
+	// Emit metrics for each container
+	for _, cont := range containers {
+		// Container info
+		ch <- prometheus.MustNewConstMetric(
+			c.containerInfo, prometheus.GaugeValue, 1,
+			cont.ID, cont.Name, cont.Image, cont.State,
+		)
+
+		// Container state
+		stateCode := stateToCode(cont.State)
+		ch <- prometheus.MustNewConstMetric(
+			c.containerState, prometheus.GaugeValue, float64(stateCode),
+			cont.ID, cont.Name,
+		)
+
+		// Container uptime, unknown without the start time of an inspect
+		if cont.Inspected {
+			var uptime float64
+			if cont.Running && !cont.Started.IsZero() {
+				uptime = time.Since(cont.Started).Seconds()
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.containerUptime, prometheus.GaugeValue, uptime,
+				cont.ID, cont.Name,
+			)
+		}
+
+		// Container created timestamp
+		if !cont.Created.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCreated, prometheus.GaugeValue, float64(cont.Created.Unix()),
+				cont.ID, cont.Name,
+			)
+		}
+
+		// Container started timestamp
+		if !cont.Started.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerStarted, prometheus.GaugeValue, float64(cont.Started.Unix()),
+				cont.ID, cont.Name,
+			)
+		}
+
+		// Restart count, only known from an inspect
+		if cont.Inspected {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerRestartCount, prometheus.GaugeValue, float64(cont.RestartCount),
+				cont.ID, cont.Name,
+			)
+		}
+
+		// Health status
+		healthCode := healthToCode(cont.Health)
+		ch <- prometheus.MustNewConstMetric(
+			c.containerHealthStatus, prometheus.GaugeValue, float64(healthCode),
+			cont.ID, cont.Name,
+		)
+
+		// Exit code
+		ch <- prometheus.MustNewConstMetric(
+			c.containerExitCode, prometheus.GaugeValue, float64(cont.ExitCode),
+			cont.ID, cont.Name,
+		)
+
+		// OOM killed, only known from an inspect
+		if cont.Inspected {
+			var oomKilled float64
+			if cont.OOMKilled {
+				oomKilled = 1
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.containerOOMKilled, prometheus.GaugeValue, oomKilled,
+				cont.ID, cont.Name,
+			)
+		}
+
+		// Network attachments
+		for _, net := range cont.Networks {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerNetworkInfo, prometheus.GaugeValue, 1,
+				cont.ID, cont.Name, net.Name, net.IPAddress,
+			)
+		}
+	}
+
+	return nil
+}
+
+// stateToCode converts container state string to numeric code
+func stateToCode(state string) int {
+	switch state {
+	case "running":
+		return 1
+	case "paused":
+		return 2
+	case "restarting":
+		return 3
+	case "exited":
+		return 4
+	case "dead":
+		return 5
+	case "created":
+		return 6
+	default:
+		return 0
+	}
+}
+
+// healthToCode converts health status string to numeric code
+func healthToCode(health string) int {
+	switch health {
+	case "healthy":
+		return 1
+	case "unhealthy":
+		return 0
+	case "starting":
+		return 2
+	default:
+		return -1
+	}
+}
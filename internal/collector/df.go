@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerSubCollector("df", false, func(c *Collector, cfg *config.Config) subCollector {
+		return newDfCollector(c.diskUsage, c.prefix)
+	})
+}
+
+// dfCollector exports the cached Docker disk usage by object type and per container
+type dfCollector struct {
+	diskUsage *diskUsageCache
+
+	usageBytes       *prometheus.Desc
+	reclaimableBytes *prometheus.Desc
+	objects          *prometheus.Desc
+	activeObjects    *prometheus.Desc
+	containerSizeRw  *prometheus.Desc
+	containerSizeFs  *prometheus.Desc
+	diskUsageAge     *prometheus.Desc
+}
+
+// newDfCollector creates a new dfCollector
+func newDfCollector(diskUsage *diskUsageCache, prefix string) *dfCollector {
+	return &dfCollector{
+		diskUsage: diskUsage,
+
+		usageBytes: prometheus.NewDesc(
+			prefix+"_disk_usage_bytes",
+			"Disk space used by object type (images, containers, volumes, build_cache)",
+			[]string{"type"}, nil,
+		),
+		reclaimableBytes: prometheus.NewDesc(
+			prefix+"_disk_usage_reclaimable_bytes",
+			"Disk space freed by pruning unused objects, by object type",
+			[]string{"type"}, nil,
+		),
+		objects: prometheus.NewDesc(
+			prefix+"_disk_usage_objects",
+			"Number of objects by type",
+			[]string{"type"}, nil,
+		),
+		activeObjects: prometheus.NewDesc(
+			prefix+"_disk_usage_active_objects",
+			"Number of objects in use by type",
+			[]string{"type"}, nil,
+		),
+		containerSizeRw: prometheus.NewDesc(
+			prefix+"_container_size_rw_bytes",
+			"Size of the container writable layer",
+			[]string{"id", "name"}, nil,
+		),
+		containerSizeFs: prometheus.NewDesc(
+			prefix+"_container_size_root_fs_bytes",
+			"Total size of the container root filesystem including image layers",
+			[]string{"id", "name"}, nil,
+		),
+		diskUsageAge: prometheus.NewDesc(
+			prefix+"_disk_usage_age_seconds",
+			"Age of the cached disk usage data",
+			nil, nil,
+		),
+	}
+}
+
+// Name implements subCollector
+func (c *dfCollector) Name() string {
+	return "df"
+}
+
+// Describe implements subCollector
+func (c *dfCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.usageBytes
+	ch <- c.reclaimableBytes
+	ch <- c.objects
+	ch <- c.activeObjects
+	ch <- c.containerSizeRw
+	ch <- c.containerSizeFs
+	ch <- c.diskUsageAge
+}
+
+// Update implements subCollector. It never queries the daemon; the data comes
+// from the disk usage cache, which is empty until its first refresh completes.
+// It fails while the last refresh failed, still serving the older data.
+func (c *dfCollector) Update(ctx context.Context, _ []docker.ContainerInfo, ch chan<- prometheus.Metric) error {
+	if c.diskUsage == nil {
+		return nil
+	}
+	usage := c.diskUsage.Get()
+	if usage == nil {
+		return c.diskUsage.Err()
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.diskUsageAge, prometheus.GaugeValue, time.Since(usage.Time).Seconds(),
+	)
+
+	for _, obj := range usage.Objects {
+		ch <- prometheus.MustNewConstMetric(c.usageBytes, prometheus.GaugeValue, float64(obj.Size), obj.Type)
+		ch <- prometheus.MustNewConstMetric(c.reclaimableBytes, prometheus.GaugeValue, float64(obj.Reclaimable), obj.Type)
+		ch <- prometheus.MustNewConstMetric(c.objects, prometheus.GaugeValue, float64(obj.Count), obj.Type)
+		ch <- prometheus.MustNewConstMetric(c.activeObjects, prometheus.GaugeValue, float64(obj.Active), obj.Type)
+	}
+
+	for _, cont := range usage.Containers {
+		ch <- prometheus.MustNewConstMetric(
+			c.containerSizeRw, prometheus.GaugeValue, float64(cont.SizeRw),
+			cont.ID, cont.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.containerSizeFs, prometheus.GaugeValue, float64(cont.SizeRootFs),
+			cont.ID, cont.Name,
+		)
+	}
+
+	return c.diskUsage.Err()
+}
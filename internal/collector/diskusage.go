@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"go.uber.org/zap"
+)
+
+// diskUsageCache refreshes /system/df on its own interval, separate from the
+// container collection, and keeps the latest result for the collectors that need it.
+// The error of the last refresh is kept too, so those collectors report the failure.
+type diskUsageCache struct {
+	client   *docker.Client
+	logger   *zap.Logger
+	interval time.Duration
+
+	mu    sync.RWMutex
+	usage *docker.DiskUsage
+	err   error
+}
+
+// newDiskUsageCache creates a new diskUsageCache
+func newDiskUsageCache(client *docker.Client, interval time.Duration, logger *zap.Logger) *diskUsageCache {
+	return &diskUsageCache{
+		client:   client,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Run refreshes the disk usage every interval until ctx is cancelled
+func (d *diskUsageCache) Run(ctx context.Context) {
+	d.logger.Info("Disk usage collection started", zap.Duration("interval", d.interval))
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh queries /system/df. The interval doubles as the deadline because the
+// call can take far longer than regular API requests.
+func (d *diskUsageCache) refresh(ctx context.Context) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, d.interval)
+	defer cancel()
+
+	usage, err := d.client.GetDiskUsage(ctx)
+	if err != nil {
+		d.logger.Error("Failed to get disk usage", zap.Error(err))
+		d.mu.Lock()
+		d.err = err
+		d.mu.Unlock()
+		return
+	}
+
+	d.mu.Lock()
+	d.usage = usage
+	d.err = nil
+	d.mu.Unlock()
+
+	d.logger.Debug("[DISK USAGE] Refreshed", zap.Duration("duration", time.Since(start)))
+}
+
+// Get returns the latest disk usage, or nil before the first successful refresh
+func (d *diskUsageCache) Get() *docker.DiskUsage {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.usage
+}
+
+// Err returns the error of the last refresh, nil when it succeeded or has not run yet
+func (d *diskUsageCache) Err() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.err
+}
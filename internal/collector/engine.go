@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerSubCollector("engine", true, func(c *Collector, cfg *config.Config) subCollector {
+		return newEngineCollector(c.client, c.prefix)
+	})
+}
+
+// engineCollector exports Docker engine information and object counts
+type engineCollector struct {
+	client *docker.Client
+
+	engineInfo      *prometheus.Desc
+	containersTotal *prometheus.Desc
+	imagesTotal     *prometheus.Desc
+}
+
+// newEngineCollector creates a new engineCollector
+func newEngineCollector(client *docker.Client, prefix string) *engineCollector {
+	return &engineCollector{
+		client: client,
+
+		engineInfo: prometheus.NewDesc(
+			prefix+"_engine_info",
+			"Docker engine information",
+			[]string{"version", "os", "arch", "kernel"}, nil,
+		),
+		containersTotal: prometheus.NewDesc(
+			prefix+"_containers_total",
+			"Total number of containers by state",
+			[]string{"state"}, nil,
+		),
+		imagesTotal: prometheus.NewDesc(
+			prefix+"_images_total",
+			"Total number of images",
+			nil, nil,
+		),
+	}
+}
+
+// Name implements subCollector
+func (c *engineCollector) Name() string {
+	return "engine"
+}
+
+// Describe implements subCollector
+func (c *engineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.engineInfo
+	ch <- c.containersTotal
+	ch <- c.imagesTotal
+}
+
+// Update implements subCollector
+func (c *engineCollector) Update(ctx context.Context, _ []docker.ContainerInfo, ch chan<- prometheus.Metric) error {
+	info, err := c.client.GetEngineInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Engine info
+	ch <- prometheus.MustNewConstMetric(
+		c.engineInfo, prometheus.GaugeValue, 1,
+		info.Version, info.OS, info.Arch, info.KernelVersion,
+	)
+
+	// Containers total by state
+	ch <- prometheus.MustNewConstMetric(
+		c.containersTotal, prometheus.GaugeValue, float64(info.ContainersRunning),
+		"running",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.containersTotal, prometheus.GaugeValue, float64(info.ContainersPaused),
+		"paused",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.containersTotal, prometheus.GaugeValue, float64(info.ContainersStopped),
+		"stopped",
+	)
+
+	// Images total
+	ch <- prometheus.MustNewConstMetric(
+		c.imagesTotal, prometheus.GaugeValue, float64(info.Images),
+	)
+
+	return nil
+}
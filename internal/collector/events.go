@@ -0,0 +1,224 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Reconnect backoff for the events stream
+const (
+	eventsMinBackoff = time.Second
+	eventsMaxBackoff = 30 * time.Second
+)
+
+// eventRetention is how long the counters of a destroyed container are kept, so a
+// container recreated under the same name keeps counting
+const eventRetention = time.Hour
+
+// watchEvents keeps the inventory up to date from the Docker events stream until ctx
+// is cancelled. The inventory is fully resynced on startup and after every reconnect,
+// and a reconnected stream resumes after the last event seen, so events missed while
+// disconnected are never lost.
+func (c *Collector) watchEvents(ctx context.Context) {
+	c.logger.Info("Watching Docker events")
+
+	var last time.Time
+	backoff := eventsMinBackoff
+	for {
+		if c.streamEvents(ctx, &last) {
+			backoff = eventsMinBackoff
+		}
+		c.inventory.Invalidate()
+
+		select {
+		case <-ctx.Done():
+			c.logger.Info("Stopped watching Docker events")
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > eventsMaxBackoff {
+			backoff = eventsMaxBackoff
+		}
+	}
+}
+
+// streamEvents subscribes to the events stream after the event time last, resyncs the
+// inventory and applies events until the stream fails. last is advanced with every
+// event. It reports whether the resync succeeded.
+func (c *Collector) streamEvents(ctx context.Context, last *time.Time) bool {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Subscribe before resyncing so no event between the two is missed
+	var since time.Time
+	if !last.IsZero() {
+		since = last.Add(time.Nanosecond)
+	}
+	msgs, errs := c.client.Events(streamCtx, since)
+
+	resyncCtx, resyncCancel := context.WithTimeout(streamCtx, c.timeout)
+	inspectErrs, err := c.inventory.Resync(resyncCtx)
+	resyncCancel()
+	if err != nil {
+		c.logger.Error("Failed to resync container inventory", zap.Error(err))
+		return false
+	}
+	c.recordInspectErrors(inspectErrs)
+	c.logger.Debug("[EVENTS] Container inventory resynced")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case err := <-errs:
+			if ctx.Err() == nil {
+				c.logger.Warn("Docker events stream disconnected", zap.Error(err))
+			}
+			return true
+		case msg := <-msgs:
+			c.logger.Debug("[EVENTS] Received event",
+				zap.String("action", string(msg.Action)),
+				zap.String("id", msg.Actor.ID))
+			if msg.TimeNano > 0 {
+				*last = time.Unix(0, msg.TimeNano)
+			}
+
+			if msg.Type == events.ContainerEventType && c.client.Filter().MatchEvent(msg) {
+				c.events.Record(msg)
+			}
+
+			handleCtx, handleCancel := context.WithTimeout(streamCtx, c.timeout)
+			if err := c.inventory.Handle(handleCtx, msg); err != nil {
+				var inspectErr *docker.InspectError
+				if errors.As(err, &inspectErr) {
+					c.inspectFailures.Record([]*docker.InspectError{inspectErr}, time.Now())
+				}
+				c.logger.Error("Failed to apply container event",
+					zap.String("action", string(msg.Action)),
+					zap.String("id", msg.Actor.ID),
+					zap.Error(err))
+			}
+			handleCancel()
+		}
+	}
+}
+
+// eventKey identifies a lifecycle event counter
+type eventKey struct {
+	name   string
+	action string
+}
+
+// eventCounters counts container lifecycle events per container name. Counters are
+// keyed by name rather than ID so they survive containers being recreated. The
+// counters of a name are dropped eventRetention after its container is destroyed.
+type eventCounters struct {
+	mu        sync.Mutex
+	counts    map[eventKey]float64
+	exitCode  map[string]float64
+	destroyed map[string]time.Time // destroy time by name, until recreated
+}
+
+// newEventCounters creates empty event counters
+func newEventCounters() *eventCounters {
+	return &eventCounters{
+		counts:    make(map[eventKey]float64),
+		exitCode:  make(map[string]float64),
+		destroyed: make(map[string]time.Time),
+	}
+}
+
+// Record counts a lifecycle event and remembers the exit code of die events
+func (e *eventCounters) Record(msg events.Message) {
+	action := string(msg.Action)
+	if strings.HasPrefix(action, string(events.ActionHealthStatus)) {
+		action = string(events.ActionHealthStatus)
+	}
+	if !countedActions[action] {
+		return
+	}
+
+	name := msg.Actor.Attributes["name"]
+	if name == "" {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.counts[eventKey{name: name, action: action}]++
+	if msg.Action == events.ActionDestroy {
+		e.destroyed[name] = time.Now()
+	} else {
+		delete(e.destroyed, name)
+	}
+	if msg.Action == events.ActionDie {
+		if code, err := strconv.Atoi(msg.Actor.Attributes["exitCode"]); err == nil {
+			e.exitCode[name] = float64(code)
+		}
+	}
+}
+
+// countedActions are the container event actions exported as counters
+var countedActions = map[string]bool{
+	string(events.ActionCreate):       true,
+	string(events.ActionStart):        true,
+	string(events.ActionRestart):      true,
+	string(events.ActionStop):         true,
+	string(events.ActionKill):         true,
+	string(events.ActionDie):          true,
+	string(events.ActionOOM):          true,
+	string(events.ActionPause):        true,
+	string(events.ActionUnPause):      true,
+	string(events.ActionDestroy):      true,
+	string(events.ActionHealthStatus): true,
+}
+
+// expire drops the counters of containers destroyed more than eventRetention ago.
+// It must be called with e.mu held.
+func (e *eventCounters) expire(now time.Time) {
+	for name, destroyed := range e.destroyed {
+		if now.Sub(destroyed) < eventRetention {
+			continue
+		}
+		for key := range e.counts {
+			if key.name == name {
+				delete(e.counts, key)
+			}
+		}
+		delete(e.exitCode, name)
+		delete(e.destroyed, name)
+	}
+}
+
+// collectEventMetrics emits the lifecycle event counters
+func (c *Collector) collectEventMetrics(ch chan<- prometheus.Metric) {
+	c.events.mu.Lock()
+	defer c.events.mu.Unlock()
+
+	c.events.expire(time.Now())
+
+	for key, count := range c.events.counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.containerEventsTotal, prometheus.CounterValue, count,
+			key.name, key.action,
+		)
+	}
+	for name, code := range c.events.exitCode {
+		ch <- prometheus.MustNewConstMetric(
+			c.containerLastExitCode, prometheus.GaugeValue, code,
+			name,
+		)
+	}
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerSubCollector("image", true, func(c *Collector, cfg *config.Config) subCollector {
+		return newImageCollector(c.client, c.diskUsage, c.prefix)
+	})
+}
+
+// imageCollector exports the image inventory
+type imageCollector struct {
+	client    *docker.Client
+	diskUsage *diskUsageCache // nil when disk usage collection is disabled
+
+	imageInfo         *prometheus.Desc
+	imageSize         *prometheus.Desc
+	imageCreated      *prometheus.Desc
+	imageContainers   *prometheus.Desc
+	imagesDangling    *prometheus.Desc
+	imagesReclaimable *prometheus.Desc
+}
+
+// newImageCollector creates a new imageCollector
+func newImageCollector(client *docker.Client, diskUsage *diskUsageCache, prefix string) *imageCollector {
+	return &imageCollector{
+		client:    client,
+		diskUsage: diskUsage,
+
+		imageInfo: prometheus.NewDesc(
+			prefix+"_image_info",
+			"Image information, one series per tag",
+			[]string{"id", "repository", "tag", "digest"}, nil,
+		),
+		imageSize: prometheus.NewDesc(
+			prefix+"_image_size_bytes",
+			"Image size in bytes",
+			[]string{"id"}, nil,
+		),
+		imageCreated: prometheus.NewDesc(
+			prefix+"_image_created_seconds",
+			"Image creation timestamp",
+			[]string{"id"}, nil,
+		),
+		imageContainers: prometheus.NewDesc(
+			prefix+"_image_containers",
+			"Number of containers using the image, stopped ones included",
+			[]string{"id"}, nil,
+		),
+		imagesDangling: prometheus.NewDesc(
+			prefix+"_images_dangling",
+			"Number of untagged images",
+			nil, nil,
+		),
+		imagesReclaimable: prometheus.NewDesc(
+			prefix+"_images_reclaimable_bytes",
+			"Image layer space freed by removing the images not used by any container, as in docker system df",
+			nil, nil,
+		),
+	}
+}
+
+// Name implements subCollector
+func (c *imageCollector) Name() string {
+	return "image"
+}
+
+// Describe implements subCollector
+func (c *imageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.imageInfo
+	ch <- c.imageSize
+	ch <- c.imageCreated
+	ch <- c.imageContainers
+	ch <- c.imagesDangling
+	ch <- c.imagesReclaimable
+}
+
+// Update implements subCollector. Image usage is counted over all containers of the
+// daemon, not only those passing the container filters.
+func (c *imageCollector) Update(ctx context.Context, _ []docker.ContainerInfo, ch chan<- prometheus.Metric) error {
+	images, err := c.client.GetImages(ctx)
+	if err != nil {
+		return err
+	}
+	usage, err := c.client.ImageUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dangling int
+	for _, img := range images {
+		// Image info (one series per tag)
+		if img.Dangling() {
+			dangling++
+			ch <- prometheus.MustNewConstMetric(
+				c.imageInfo, prometheus.GaugeValue, 1,
+				img.ID, "", "", imageDigest(img, ""),
+			)
+		}
+		for _, ref := range img.Tags {
+			repository, tag := docker.SplitImageReference(ref)
+			ch <- prometheus.MustNewConstMetric(
+				c.imageInfo, prometheus.GaugeValue, 1,
+				img.ID, repository, tag, imageDigest(img, repository),
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.imageSize, prometheus.GaugeValue, float64(img.Size),
+			img.ID,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.imageCreated, prometheus.GaugeValue, float64(img.Created.Unix()),
+			img.ID,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.imageContainers, prometheus.GaugeValue, float64(usage[img.ID]),
+			img.ID,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.imagesDangling, prometheus.GaugeValue, float64(dangling),
+	)
+	// Reclaimable space needs the total layer size, which only /system/df reports
+	if c.diskUsage == nil {
+		return nil
+	}
+	if du := c.diskUsage.Get(); du != nil {
+		for _, obj := range du.Objects {
+			if obj.Type == docker.DiskUsageImages {
+				ch <- prometheus.MustNewConstMetric(
+					c.imagesReclaimable, prometheus.GaugeValue, float64(obj.Reclaimable),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// imageDigest returns the digest of the image in the given repository, falling back
+// to the first known digest
+func imageDigest(img docker.ImageInfo, repository string) string {
+	var fallback string
+	for _, ref := range img.Digests {
+		repo, digest := docker.SplitImageReference(ref)
+		if repo == repository {
+			return digest
+		}
+		if fallback == "" {
+			fallback = digest
+		}
+	}
+	return fallback
+}
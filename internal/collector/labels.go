@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// labelMatcher selects Docker labels using an allowlist of glob patterns
+type labelMatcher struct {
+	patterns []string
+}
+
+// newLabelMatcher creates a labelMatcher for the given glob patterns
+func newLabelMatcher(patterns []string) *labelMatcher {
+	return &labelMatcher{patterns: patterns}
+}
+
+// Enabled reports whether any label pattern is configured
+func (m *labelMatcher) Enabled() bool {
+	return len(m.patterns) > 0
+}
+
+// Match reports whether a Docker label key is allowed
+func (m *labelMatcher) Match(key string) bool {
+	for _, pattern := range m.patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the allowed Docker labels keyed by sanitized Prometheus label name.
+// When two Docker labels sanitize to the same name, the lexically first key wins.
+func (m *labelMatcher) Select(labels map[string]string) map[string]string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		if m.Match(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		name := sanitizeLabelName(key)
+		if _, exists := result[name]; !exists {
+			result[name] = labels[key]
+		}
+	}
+	return result
+}
+
+// sanitizeLabelName converts a Docker label key into a valid Prometheus label name
+func sanitizeLabelName(key string) string {
+	var b strings.Builder
+	b.WriteString("label_")
+	for _, r := range key {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// collectContainerLabels emits the container labels info metric. The label set is
+// the union of the selected labels across all containers, so every series of the
+// metric family carries the same label names.
+func (c *containerCollector) collectContainerLabels(containers []docker.ContainerInfo, ch chan<- prometheus.Metric) {
+	if !c.labels.Enabled() {
+		return
+	}
+
+	selected := make([]map[string]string, len(containers))
+	nameSet := make(map[string]struct{})
+	for i, cont := range containers {
+		selected[i] = c.labels.Select(cont.Labels)
+		for name := range selected[i] {
+			nameSet[name] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	desc := prometheus.NewDesc(
+		c.prefix+"_container_labels",
+		"Container labels selected by --container-label",
+		append([]string{"id", "name"}, names...), nil,
+	)
+
+	for i, cont := range containers {
+		values := make([]string, 0, len(names)+2)
+		values = append(values, cont.ID, cont.Name)
+		for _, name := range names {
+			values = append(values, selected[i][name])
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, values...)
+	}
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerSubCollector("network", true, func(c *Collector, cfg *config.Config) subCollector {
+		return newNetworkCollector(c.client, c.prefix)
+	})
+}
+
+// networkCollector exports Docker networks and their IPAM address usage
+type networkCollector struct {
+	client *docker.Client
+
+	networkInfo       *prometheus.Desc
+	networkContainers *prometheus.Desc
+	subnetSize        *prometheus.Desc
+	subnetAllocated   *prometheus.Desc
+	subnetUsageRatio  *prometheus.Desc
+}
+
+// newNetworkCollector creates a new networkCollector
+func newNetworkCollector(client *docker.Client, prefix string) *networkCollector {
+	return &networkCollector{
+		client: client,
+
+		networkInfo: prometheus.NewDesc(
+			prefix+"_network_info",
+			"Network information",
+			[]string{"id", "name", "driver", "scope"}, nil,
+		),
+		networkContainers: prometheus.NewDesc(
+			prefix+"_network_containers",
+			"Number of containers attached to the network",
+			[]string{"id", "name"}, nil,
+		),
+		subnetSize: prometheus.NewDesc(
+			prefix+"_network_subnet_addresses",
+			"Addresses containers can be assigned from the IPAM subnet or its ip-range, excluding the gateway and reserved addresses",
+			[]string{"id", "name", "subnet"}, nil,
+		),
+		subnetAllocated: prometheus.NewDesc(
+			prefix+"_network_subnet_allocated_addresses",
+			"Addresses assigned to containers from the IPAM subnet or its ip-range",
+			[]string{"id", "name", "subnet"}, nil,
+		),
+		subnetUsageRatio: prometheus.NewDesc(
+			prefix+"_network_subnet_usage_ratio",
+			"Assigned addresses relative to the assignable addresses of the IPAM subnet",
+			[]string{"id", "name", "subnet"}, nil,
+		),
+	}
+}
+
+// Name implements subCollector
+func (c *networkCollector) Name() string {
+	return "network"
+}
+
+// Describe implements subCollector
+func (c *networkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.networkInfo
+	ch <- c.networkContainers
+	ch <- c.subnetSize
+	ch <- c.subnetAllocated
+	ch <- c.subnetUsageRatio
+}
+
+// Update implements subCollector
+func (c *networkCollector) Update(ctx context.Context, _ []docker.ContainerInfo, ch chan<- prometheus.Metric) error {
+	networks, err := c.client.ListNetworks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, net := range networks {
+		ch <- prometheus.MustNewConstMetric(
+			c.networkInfo, prometheus.GaugeValue, 1,
+			net.ID, net.Name, net.Driver, net.Scope,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.networkContainers, prometheus.GaugeValue, float64(net.Containers),
+			net.ID, net.Name,
+		)
+
+		for _, subnet := range net.Subnets {
+			ch <- prometheus.MustNewConstMetric(
+				c.subnetSize, prometheus.GaugeValue, subnet.Size,
+				net.ID, net.Name, subnet.Subnet,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.subnetAllocated, prometheus.GaugeValue, float64(subnet.Allocated),
+				net.ID, net.Name, subnet.Subnet,
+			)
+			if subnet.Size > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					c.subnetUsageRatio, prometheus.GaugeValue, float64(subnet.Allocated)/subnet.Size,
+					net.ID, net.Name, subnet.Subnet,
+				)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// scrapeContainerList is the collector name of the container list shared by the sub-collectors
+const scrapeContainerList = "container_list"
+
+// collectorResult is the outcome of one collection step or sub-collector
+type collectorResult struct {
+	Name     string
+	Success  bool
+	Duration time.Duration
+}
+
+// scrapeErrorKey identifies a scrape error counter
+type scrapeErrorKey struct {
+	collector string
+	reason    string
+}
+
+// scrapeErrors counts collection errors by collector and reason since startup
+type scrapeErrors struct {
+	mu     sync.Mutex
+	counts map[scrapeErrorKey]float64
+}
+
+// newScrapeErrors creates empty scrapeErrors
+func newScrapeErrors() *scrapeErrors {
+	return &scrapeErrors{counts: make(map[scrapeErrorKey]float64)}
+}
+
+// Record counts an error of a collector
+func (s *scrapeErrors) Record(collector string, err error) {
+	key := scrapeErrorKey{collector: collector, reason: docker.ErrorReason(err)}
+
+	s.mu.Lock()
+	s.counts[key]++
+	s.mu.Unlock()
+}
+
+// result builds the result of a collection step started at start and records its errors
+func (c *Collector) result(name string, start time.Time, errs ...error) collectorResult {
+	res := collectorResult{Name: name, Success: true, Duration: time.Since(start)}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		res.Success = false
+		c.scrapeErrors.Record(name, err)
+	}
+	return res
+}
+
+// collectScrapeResults emits the per-collector results of a snapshot
+func (c *Collector) collectScrapeResults(results []collectorResult, ch chan<- prometheus.Metric) {
+	for _, res := range results {
+		var success float64
+		if res.Success {
+			success = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.scrapeCollectorSuccess, prometheus.GaugeValue, success,
+			res.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.scrapeCollectorDuration, prometheus.GaugeValue, res.Duration.Seconds(),
+			res.Name,
+		)
+	}
+}
+
+// collectScrapeErrors emits the scrape error counters
+func (c *Collector) collectScrapeErrors(ch chan<- prometheus.Metric) {
+	c.scrapeErrors.mu.Lock()
+	defer c.scrapeErrors.mu.Unlock()
+
+	for key, count := range c.scrapeErrors.counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.scrapeErrorsTotal, prometheus.CounterValue, count,
+			key.collector, key.reason,
+		)
+	}
+}
+
+// inspectFailureRetention is how long the failure counter of a container is kept
+// after it last failed or was listed
+const inspectFailureRetention = time.Hour
+
+// inspectFailureKey identifies an inspect failure counter
+type inspectFailureKey struct {
+	id   string
+	name string
+}
+
+// inspectFailure is the failure count of a container and when it was last seen,
+// failing or listed
+type inspectFailure struct {
+	count float64
+	seen  time.Time
+}
+
+// inspectFailures counts failed container inspects by container, so containers left
+// out of the container list are still visible. Counters of containers that neither
+// fail nor are listed anymore are dropped after inspectFailureRetention.
+type inspectFailures struct {
+	mu     sync.Mutex
+	counts map[inspectFailureKey]*inspectFailure
+}
+
+// newInspectFailures creates empty inspectFailures
+func newInspectFailures() *inspectFailures {
+	return &inspectFailures{counts: make(map[inspectFailureKey]*inspectFailure)}
+}
+
+// Record counts the failed inspects of one container list
+func (f *inspectFailures) Record(errs []*docker.InspectError, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, err := range errs {
+		key := inspectFailureKey{id: err.ID, name: err.Name}
+		failure := f.counts[key]
+		if failure == nil {
+			failure = &inspectFailure{}
+			f.counts[key] = failure
+		}
+		failure.count++
+		failure.seen = now
+	}
+}
+
+// Prune refreshes the counters of the listed containers and drops those of containers
+// gone for longer than inspectFailureRetention
+func (f *inspectFailures) Prune(containers []docker.ContainerInfo, now time.Time) {
+	listed := make(map[string]bool, len(containers))
+	for _, cont := range containers {
+		listed[cont.ID] = true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, failure := range f.counts {
+		switch {
+		case listed[key.id]:
+			failure.seen = now
+		case now.Sub(failure.seen) > inspectFailureRetention:
+			delete(f.counts, key)
+		}
+	}
+}
+
+// collectInspectFailures emits the inspect failure counters
+func (c *Collector) collectInspectFailures(ch chan<- prometheus.Metric) {
+	c.inspectFailures.mu.Lock()
+	defer c.inspectFailures.mu.Unlock()
+
+	for key, failure := range c.inspectFailures.counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.containerInspectFailures, prometheus.CounterValue, failure.count,
+			key.id, key.name,
+		)
+	}
+}
@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// snapshot holds the Docker state gathered in one collection pass
+type snapshot struct {
+	Time    time.Time
+	Up      bool // the container list was fetched
+	Metrics []prometheus.Metric
+	Results []collectorResult
+}
+
+// gather queries the Docker API and returns a new snapshot.
+// Failed calls are logged and leave the corresponding part of the snapshot empty.
+func (c *Collector) gather(ctx context.Context) *snapshot {
+	snap := &snapshot{Time: time.Now()}
+
+	start := time.Now()
+	containers, err := c.listContainers(ctx)
+	snap.Up = err == nil
+	snap.Results = append(snap.Results, c.result(scrapeContainerList, start, err))
+
+	metrics, results := c.updateSubCollectors(ctx, containers)
+	snap.Metrics = metrics
+	snap.Results = append(snap.Results, results...)
+
+	return snap
+}
+
+// Run starts the configured background work (snapshot refresh, events watch and
+// disk usage refresh) and blocks until ctx is cancelled. It returns immediately
+// when none is enabled.
+func (c *Collector) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	if c.inventory != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.watchEvents(ctx)
+		}()
+	}
+
+	if c.diskUsage != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.diskUsage.Run(ctx)
+		}()
+	}
+
+	if c.interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.collectLoop(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// collectLoop refreshes the snapshot every collect interval until ctx is cancelled
+func (c *Collector) collectLoop(ctx context.Context) {
+	c.logger.Info("Background collection started", zap.Duration("interval", c.interval))
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			c.logger.Info("Background collection stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh gathers a new snapshot and makes it the one served on scrape. It may
+// take up to one collect interval, so a slow refresh never overlaps the next one.
+func (c *Collector) refresh(ctx context.Context) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, c.interval)
+	defer cancel()
+
+	snap := c.gather(ctx)
+
+	c.mu.Lock()
+	c.snap = snap
+	c.mu.Unlock()
+
+	c.logger.Debug("[COLLECTOR] Snapshot refreshed",
+		zap.Int("metrics", len(snap.Metrics)),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// latest returns the most recent snapshot, or nil before the first refresh
+func (c *Collector) latest() *snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snap
+}
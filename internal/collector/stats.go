@@ -0,0 +1,700 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+func init() {
+	registerSubCollector("stats", true, func(c *Collector, cfg *config.Config) subCollector {
+		return newStatsCollector(c.client, c.prefix, cfg.StatsConcurrency, cfg.StatsTimeout, c.logger)
+	})
+}
+
+// statsCollector fetches the resource usage of the running containers and exports
+// it with the CPU limits from the container configuration
+type statsCollector struct {
+	client      *docker.Client
+	logger      *zap.Logger
+	concurrency int           // concurrent stats calls
+	timeout     time.Duration // deadline of each stats call (0 uses the collection deadline)
+
+	// Last stats of each running container, served when a later call times out
+	mu       sync.Mutex
+	last     map[string]lastStats
+	timeouts float64
+
+	// Stats collection metrics
+	containerStatsStale *prometheus.Desc
+	statsTimeoutsTotal  *prometheus.Desc
+
+	// CPU metrics
+	containerCPUPercent          *prometheus.Desc
+	containerCPUUsageSeconds     *prometheus.Desc
+	containerCPUUserSeconds      *prometheus.Desc
+	containerCPUKernelSeconds    *prometheus.Desc
+	containerCPUPeriods          *prometheus.Desc
+	containerCPUThrottledPeriods *prometheus.Desc
+	containerCPUThrottledSeconds *prometheus.Desc
+	containerCPULimitCores       *prometheus.Desc
+	containerCPUQuota            *prometheus.Desc
+	containerCPUPeriod           *prometheus.Desc
+	containerCPUShares           *prometheus.Desc
+
+	// Memory metrics
+	containerMemoryUsage      *prometheus.Desc
+	containerMemoryLimit      *prometheus.Desc
+	containerMemoryPercent    *prometheus.Desc
+	containerMemoryWorkingSet *prometheus.Desc
+	containerMemoryRSS        *prometheus.Desc
+	containerMemoryCache      *prometheus.Desc
+	containerMemorySwap       *prometheus.Desc
+	containerMemoryPageFaults *prometheus.Desc
+
+	// Network metrics
+	containerNetworkRxBytes   *prometheus.Desc
+	containerNetworkTxBytes   *prometheus.Desc
+	containerNetworkRxPackets *prometheus.Desc
+	containerNetworkTxPackets *prometheus.Desc
+	containerNetworkRxErrors  *prometheus.Desc
+	containerNetworkTxErrors  *prometheus.Desc
+	containerNetworkRxDropped *prometheus.Desc
+	containerNetworkTxDropped *prometheus.Desc
+
+	// Block I/O metrics
+	containerBlkioReadBytes         *prometheus.Desc
+	containerBlkioWriteBytes        *prometheus.Desc
+	containerBlkioDeviceBytes       *prometheus.Desc
+	containerBlkioDeviceOps         *prometheus.Desc
+	containerBlkioDeviceServiceTime *prometheus.Desc
+	containerBlkioDeviceWaitTime    *prometheus.Desc
+
+	// PIDs metrics
+	containerPids           *prometheus.Desc
+	containerPidsLimit      *prometheus.Desc
+	containerPidsUsageRatio *prometheus.Desc
+
+	// Pressure metrics (cgroup v2 stats source only)
+	containerPressureStall *prometheus.Desc
+}
+
+// lastStats is the last successful stats call of a container
+type lastStats struct {
+	stats *docker.ContainerStats
+	time  time.Time
+}
+
+// newStatsCollector creates a new statsCollector
+func newStatsCollector(client *docker.Client, prefix string, concurrency int, timeout time.Duration, logger *zap.Logger) *statsCollector {
+	return &statsCollector{
+		client:      client,
+		logger:      logger,
+		concurrency: max(concurrency, 1),
+		timeout:     timeout,
+		last:        make(map[string]lastStats),
+
+		// Stats collection metrics
+		containerStatsStale: prometheus.NewDesc(
+			prefix+"_container_stats_stale",
+			"Whether the container stats are the last-known values because the stats call timed out (1=stale, 0=fresh)",
+			[]string{"id", "name"}, nil,
+		),
+		statsTimeoutsTotal: prometheus.NewDesc(
+			prefix+"_container_stats_timeouts_total",
+			"Container stats calls that timed out",
+			nil, nil,
+		),
+
+		// CPU metrics
+		containerCPUPercent: prometheus.NewDesc(
+			prefix+"_container_cpu_usage_percent",
+			"Container CPU usage percentage",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUUsageSeconds: prometheus.NewDesc(
+			prefix+"_container_cpu_usage_seconds_total",
+			"Container total CPU usage in seconds",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUUserSeconds: prometheus.NewDesc(
+			prefix+"_container_cpu_user_seconds_total",
+			"Container CPU time spent in user mode in seconds",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUKernelSeconds: prometheus.NewDesc(
+			prefix+"_container_cpu_kernel_seconds_total",
+			"Container CPU time spent in kernel mode in seconds",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUPeriods: prometheus.NewDesc(
+			prefix+"_container_cpu_cfs_periods_total",
+			"Container CFS enforcement periods that elapsed",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUThrottledPeriods: prometheus.NewDesc(
+			prefix+"_container_cpu_cfs_throttled_periods_total",
+			"Container CFS periods in which the container was throttled",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUThrottledSeconds: prometheus.NewDesc(
+			prefix+"_container_cpu_cfs_throttled_seconds_total",
+			"Container total time throttled in seconds",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPULimitCores: prometheus.NewDesc(
+			prefix+"_container_cpu_limit_cores",
+			"Container CPU limit in cores from --cpus or the CFS quota (only for limited containers)",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUQuota: prometheus.NewDesc(
+			prefix+"_container_cpu_quota_microseconds",
+			"Container CFS quota per period in microseconds (only when set)",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUPeriod: prometheus.NewDesc(
+			prefix+"_container_cpu_period_microseconds",
+			"Container CFS period in microseconds (only when set)",
+			[]string{"id", "name"}, nil,
+		),
+		containerCPUShares: prometheus.NewDesc(
+			prefix+"_container_cpu_shares",
+			"Container CPU shares, the relative weight against other containers (only when set)",
+			[]string{"id", "name"}, nil,
+		),
+
+		// Memory metrics
+		containerMemoryUsage: prometheus.NewDesc(
+			prefix+"_container_memory_usage_bytes",
+			"Container memory usage in bytes",
+			[]string{"id", "name"}, nil,
+		),
+		containerMemoryLimit: prometheus.NewDesc(
+			prefix+"_container_memory_limit_bytes",
+			"Container memory limit in bytes",
+			[]string{"id", "name"}, nil,
+		),
+		containerMemoryPercent: prometheus.NewDesc(
+			prefix+"_container_memory_usage_percent",
+			"Container memory working set as a percentage of the limit",
+			[]string{"id", "name"}, nil,
+		),
+		containerMemoryWorkingSet: prometheus.NewDesc(
+			prefix+"_container_memory_working_set_bytes",
+			"Container memory usage minus inactive page cache",
+			[]string{"id", "name"}, nil,
+		),
+		containerMemoryRSS: prometheus.NewDesc(
+			prefix+"_container_memory_rss_bytes",
+			"Container anonymous memory (rss on cgroup v1, anon on cgroup v2)",
+			[]string{"id", "name"}, nil,
+		),
+		containerMemoryCache: prometheus.NewDesc(
+			prefix+"_container_memory_cache_bytes",
+			"Container page cache memory (cache on cgroup v1, file on cgroup v2)",
+			[]string{"id", "name"}, nil,
+		),
+		containerMemorySwap: prometheus.NewDesc(
+			prefix+"_container_memory_swap_bytes",
+			"Container swap usage",
+			[]string{"id", "name"}, nil,
+		),
+		containerMemoryPageFaults: prometheus.NewDesc(
+			prefix+"_container_memory_page_faults_total",
+			"Container page faults by type (major, minor)",
+			[]string{"id", "name", "type"}, nil,
+		),
+
+		// Network metrics
+		containerNetworkRxBytes: prometheus.NewDesc(
+			prefix+"_container_network_rx_bytes_total",
+			"Container network bytes received",
+			[]string{"id", "name", "interface"}, nil,
+		),
+		containerNetworkTxBytes: prometheus.NewDesc(
+			prefix+"_container_network_tx_bytes_total",
+			"Container network bytes transmitted",
+			[]string{"id", "name", "interface"}, nil,
+		),
+		containerNetworkRxPackets: prometheus.NewDesc(
+			prefix+"_container_network_rx_packets_total",
+			"Container network packets received",
+			[]string{"id", "name", "interface"}, nil,
+		),
+		containerNetworkTxPackets: prometheus.NewDesc(
+			prefix+"_container_network_tx_packets_total",
+			"Container network packets transmitted",
+			[]string{"id", "name", "interface"}, nil,
+		),
+		containerNetworkRxErrors: prometheus.NewDesc(
+			prefix+"_container_network_rx_errors_total",
+			"Container network receive errors",
+			[]string{"id", "name", "interface"}, nil,
+		),
+		containerNetworkTxErrors: prometheus.NewDesc(
+			prefix+"_container_network_tx_errors_total",
+			"Container network transmit errors",
+			[]string{"id", "name", "interface"}, nil,
+		),
+		containerNetworkRxDropped: prometheus.NewDesc(
+			prefix+"_container_network_rx_dropped_total",
+			"Container network received packets dropped",
+			[]string{"id", "name", "interface"}, nil,
+		),
+		containerNetworkTxDropped: prometheus.NewDesc(
+			prefix+"_container_network_tx_dropped_total",
+			"Container network transmitted packets dropped",
+			[]string{"id", "name", "interface"}, nil,
+		),
+
+		// Block I/O metrics
+		containerBlkioReadBytes: prometheus.NewDesc(
+			prefix+"_container_blkio_read_bytes_total",
+			"Container block I/O bytes read",
+			[]string{"id", "name"}, nil,
+		),
+		containerBlkioWriteBytes: prometheus.NewDesc(
+			prefix+"_container_blkio_write_bytes_total",
+			"Container block I/O bytes written",
+			[]string{"id", "name"}, nil,
+		),
+		containerBlkioDeviceBytes: prometheus.NewDesc(
+			prefix+"_container_blkio_device_bytes_total",
+			"Container block I/O bytes per device and operation",
+			[]string{"id", "name", "device", "device_name", "op"}, nil,
+		),
+		containerBlkioDeviceOps: prometheus.NewDesc(
+			prefix+"_container_blkio_device_ops_total",
+			"Container block I/O operations per device and operation",
+			[]string{"id", "name", "device", "device_name", "op"}, nil,
+		),
+		containerBlkioDeviceServiceTime: prometheus.NewDesc(
+			prefix+"_container_blkio_device_service_seconds_total",
+			"Container block I/O service time per device (cgroup v1 only)",
+			[]string{"id", "name", "device", "device_name"}, nil,
+		),
+		containerBlkioDeviceWaitTime: prometheus.NewDesc(
+			prefix+"_container_blkio_device_wait_seconds_total",
+			"Container block I/O time spent waiting in the scheduler queue per device (cgroup v1 only)",
+			[]string{"id", "name", "device", "device_name"}, nil,
+		),
+
+		// PIDs metrics
+		containerPids: prometheus.NewDesc(
+			prefix+"_container_pids",
+			"Number of processes and threads in the container",
+			[]string{"id", "name"}, nil,
+		),
+		containerPidsLimit: prometheus.NewDesc(
+			prefix+"_container_pids_limit",
+			"Maximum number of processes and threads allowed in the container",
+			[]string{"id", "name"}, nil,
+		),
+		containerPidsUsageRatio: prometheus.NewDesc(
+			prefix+"_container_pids_usage_ratio",
+			"Ratio of current PIDs to the PIDs limit (only for containers with a limit)",
+			[]string{"id", "name"}, nil,
+		),
+
+		// Pressure metrics
+		containerPressureStall: prometheus.NewDesc(
+			prefix+"_container_pressure_stall_seconds_total",
+			"Time tasks of the container stalled on a resource (some=at least one task, full=all tasks)",
+			[]string{"id", "name", "resource", "kind"}, nil,
+		),
+	}
+}
+
+// Name implements subCollector
+func (c *statsCollector) Name() string {
+	return "stats"
+}
+
+// Describe implements subCollector
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.containerStatsStale
+	ch <- c.statsTimeoutsTotal
+	ch <- c.containerCPUPercent
+	ch <- c.containerCPUUsageSeconds
+	ch <- c.containerCPUUserSeconds
+	ch <- c.containerCPUKernelSeconds
+	ch <- c.containerCPUPeriods
+	ch <- c.containerCPUThrottledPeriods
+	ch <- c.containerCPUThrottledSeconds
+	ch <- c.containerCPULimitCores
+	ch <- c.containerCPUQuota
+	ch <- c.containerCPUPeriod
+	ch <- c.containerCPUShares
+	ch <- c.containerMemoryUsage
+	ch <- c.containerMemoryLimit
+	ch <- c.containerMemoryPercent
+	ch <- c.containerMemoryWorkingSet
+	ch <- c.containerMemoryRSS
+	ch <- c.containerMemoryCache
+	ch <- c.containerMemorySwap
+	ch <- c.containerMemoryPageFaults
+	ch <- c.containerNetworkRxBytes
+	ch <- c.containerNetworkTxBytes
+	ch <- c.containerNetworkRxPackets
+	ch <- c.containerNetworkTxPackets
+	ch <- c.containerNetworkRxErrors
+	ch <- c.containerNetworkTxErrors
+	ch <- c.containerNetworkRxDropped
+	ch <- c.containerNetworkTxDropped
+	ch <- c.containerBlkioReadBytes
+	ch <- c.containerBlkioWriteBytes
+	ch <- c.containerBlkioDeviceBytes
+	ch <- c.containerBlkioDeviceOps
+	ch <- c.containerBlkioDeviceServiceTime
+	ch <- c.containerBlkioDeviceWaitTime
+	ch <- c.containerPids
+	ch <- c.containerPidsLimit
+	ch <- c.containerPidsUsageRatio
+	ch <- c.containerPressureStall
+}
+
+// Update implements subCollector. Containers whose stats fail are skipped and their
+// errors returned together, so the stats of the others are still exported. Containers
+// whose stats call timed out keep their last-known stats, marked stale.
+func (c *statsCollector) Update(ctx context.Context, containers []docker.ContainerInfo, ch chan<- prometheus.Metric) error {
+	statsMap, stale, errs := c.fetchStats(ctx, containers)
+
+	c.mu.Lock()
+	timeouts := c.timeouts
+	c.mu.Unlock()
+	ch <- prometheus.MustNewConstMetric(c.statsTimeoutsTotal, prometheus.CounterValue, timeouts)
+
+	for _, cont := range containers {
+		// CPU limits
+		if limit := cont.CPULimitCores(); limit > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPULimitCores, prometheus.GaugeValue, limit,
+				cont.ID, cont.Name,
+			)
+		}
+		if cont.CPUQuota > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUQuota, prometheus.GaugeValue, float64(cont.CPUQuota),
+				cont.ID, cont.Name,
+			)
+		}
+		if cont.CPUPeriod > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUPeriod, prometheus.GaugeValue, float64(cont.CPUPeriod),
+				cont.ID, cont.Name,
+			)
+		}
+		if cont.CPUShares > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUShares, prometheus.GaugeValue, float64(cont.CPUShares),
+				cont.ID, cont.Name,
+			)
+		}
+
+		// Resource metrics (only for running containers)
+		if stats, ok := statsMap[cont.ID]; ok {
+			var staleValue float64
+			if stale[cont.ID] {
+				staleValue = 1
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.containerStatsStale, prometheus.GaugeValue, staleValue,
+				cont.ID, cont.Name,
+			)
+
+			// CPU
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUPercent, prometheus.GaugeValue, stats.CPUPercent,
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUUsageSeconds, prometheus.CounterValue,
+				float64(stats.CPUUsageTotal)/1e9, // nanoseconds to seconds
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUUserSeconds, prometheus.CounterValue, float64(stats.CPUUser)/1e9,
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUKernelSeconds, prometheus.CounterValue, float64(stats.CPUKernel)/1e9,
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUPeriods, prometheus.CounterValue, float64(stats.CPUPeriods),
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUThrottledPeriods, prometheus.CounterValue, float64(stats.CPUThrottledPeriods),
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerCPUThrottledSeconds, prometheus.CounterValue, float64(stats.CPUThrottledTime)/1e9,
+				cont.ID, cont.Name,
+			)
+
+			// Memory
+			ch <- prometheus.MustNewConstMetric(
+				c.containerMemoryUsage, prometheus.GaugeValue, float64(stats.MemoryUsage),
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerMemoryLimit, prometheus.GaugeValue, float64(stats.MemoryLimit),
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerMemoryPercent, prometheus.GaugeValue, stats.MemoryPercent,
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerMemoryWorkingSet, prometheus.GaugeValue, float64(stats.MemoryWorkingSet),
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerMemoryRSS, prometheus.GaugeValue, float64(stats.MemoryRSS),
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerMemoryCache, prometheus.GaugeValue, float64(stats.MemoryCache),
+				cont.ID, cont.Name,
+			)
+			if stats.HasMemorySwap {
+				ch <- prometheus.MustNewConstMetric(
+					c.containerMemorySwap, prometheus.GaugeValue, float64(stats.MemorySwap),
+					cont.ID, cont.Name,
+				)
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.containerMemoryPageFaults, prometheus.CounterValue, float64(stats.MemoryPgMajFault),
+				cont.ID, cont.Name, "major",
+			)
+			if stats.MemoryPgFault >= stats.MemoryPgMajFault {
+				// pgfault counts all faults, major ones included
+				ch <- prometheus.MustNewConstMetric(
+					c.containerMemoryPageFaults, prometheus.CounterValue,
+					float64(stats.MemoryPgFault-stats.MemoryPgMajFault),
+					cont.ID, cont.Name, "minor",
+				)
+			}
+
+			// Network (per interface)
+			for iface, netStats := range stats.Networks {
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkRxBytes, prometheus.CounterValue, float64(netStats.RxBytes),
+					cont.ID, cont.Name, iface,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkTxBytes, prometheus.CounterValue, float64(netStats.TxBytes),
+					cont.ID, cont.Name, iface,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkRxPackets, prometheus.CounterValue, float64(netStats.RxPackets),
+					cont.ID, cont.Name, iface,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkTxPackets, prometheus.CounterValue, float64(netStats.TxPackets),
+					cont.ID, cont.Name, iface,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkRxErrors, prometheus.CounterValue, float64(netStats.RxErrors),
+					cont.ID, cont.Name, iface,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkTxErrors, prometheus.CounterValue, float64(netStats.TxErrors),
+					cont.ID, cont.Name, iface,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkRxDropped, prometheus.CounterValue, float64(netStats.RxDropped),
+					cont.ID, cont.Name, iface,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerNetworkTxDropped, prometheus.CounterValue, float64(netStats.TxDropped),
+					cont.ID, cont.Name, iface,
+				)
+			}
+
+			// Block I/O
+			ch <- prometheus.MustNewConstMetric(
+				c.containerBlkioReadBytes, prometheus.CounterValue, float64(stats.BlockRead),
+				cont.ID, cont.Name,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.containerBlkioWriteBytes, prometheus.CounterValue, float64(stats.BlockWrite),
+				cont.ID, cont.Name,
+			)
+
+			// Block I/O (per device)
+			for device, dev := range stats.BlockDevices {
+				ch <- prometheus.MustNewConstMetric(
+					c.containerBlkioDeviceBytes, prometheus.CounterValue, float64(dev.ReadBytes),
+					cont.ID, cont.Name, device, dev.Name, "read",
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerBlkioDeviceBytes, prometheus.CounterValue, float64(dev.WriteBytes),
+					cont.ID, cont.Name, device, dev.Name, "write",
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerBlkioDeviceOps, prometheus.CounterValue, float64(dev.ReadOps),
+					cont.ID, cont.Name, device, dev.Name, "read",
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerBlkioDeviceOps, prometheus.CounterValue, float64(dev.WriteOps),
+					cont.ID, cont.Name, device, dev.Name, "write",
+				)
+				if dev.ServiceTime > 0 || dev.WaitTime > 0 {
+					ch <- prometheus.MustNewConstMetric(
+						c.containerBlkioDeviceServiceTime, prometheus.CounterValue, float64(dev.ServiceTime)/1e9,
+						cont.ID, cont.Name, device, dev.Name,
+					)
+					ch <- prometheus.MustNewConstMetric(
+						c.containerBlkioDeviceWaitTime, prometheus.CounterValue, float64(dev.WaitTime)/1e9,
+						cont.ID, cont.Name, device, dev.Name,
+					)
+				}
+			}
+
+			// PIDs
+			ch <- prometheus.MustNewConstMetric(
+				c.containerPids, prometheus.GaugeValue, float64(stats.PidsCount),
+				cont.ID, cont.Name,
+			)
+			if stats.PidsLimit > 0 {
+				ch <- prometheus.MustNewConstMetric(
+					c.containerPidsLimit, prometheus.GaugeValue, float64(stats.PidsLimit),
+					cont.ID, cont.Name,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					c.containerPidsUsageRatio, prometheus.GaugeValue,
+					float64(stats.PidsCount)/float64(stats.PidsLimit),
+					cont.ID, cont.Name,
+				)
+			}
+
+			// Pressure
+			for _, p := range stats.Pressure {
+				ch <- prometheus.MustNewConstMetric(
+					c.containerPressureStall, prometheus.CounterValue, float64(p.Total)/1e6, // microseconds to seconds
+					cont.ID, cont.Name, p.Resource, p.Kind,
+				)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetchStats fetches stats for the running containers with a bounded number of
+// concurrent calls, the containers with the oldest stats first. It returns the stats
+// by container ID, the containers served with last-known stats after a timeout, and
+// the errors of the containers left without stats. Timeouts served from the last-known
+// stats are only counted in the timeouts counter.
+func (c *statsCollector) fetchStats(ctx context.Context, containers []docker.ContainerInfo) (map[string]*docker.ContainerStats, map[string]bool, []error) {
+	c.logger.Debug("[STEP 3/4] Collecting stats for running containers...")
+	var running []docker.ContainerInfo
+	for _, cont := range containers {
+		c.logger.Debug("[CONTAINER] Processing",
+			zap.String("id", cont.ID),
+			zap.String("name", cont.Name),
+			zap.String("image", cont.Image),
+			zap.String("state", cont.State),
+			zap.Bool("running", cont.Running),
+			zap.String("health", cont.Health))
+
+		if cont.Running {
+			running = append(running, cont)
+		}
+	}
+
+	// Containers that timed out last time go first, so a collection deadline that is
+	// too short for all containers still refreshes each of them in turn
+	c.mu.Lock()
+	sort.SliceStable(running, func(i, j int) bool {
+		return c.last[running[i].ID].time.Before(c.last[running[j].ID].time)
+	})
+	c.mu.Unlock()
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]*docker.ContainerStats, len(running))
+		errs    = make([]error, len(running))
+		jobs    = make(chan int)
+	)
+	for range min(c.concurrency, len(running)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = c.containerStats(ctx, running[i])
+			}
+		}()
+	}
+	for i := range running {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	statsMap := make(map[string]*docker.ContainerStats, len(running))
+	stale := make(map[string]bool)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var failed []error
+	last := make(map[string]lastStats, len(running))
+	for i, cont := range running {
+		switch {
+		case errs[i] == nil:
+			statsMap[cont.ID] = results[i]
+			last[cont.ID] = lastStats{stats: results[i], time: now}
+		case docker.ErrorReason(errs[i]) == docker.ReasonTimeout:
+			c.timeouts++
+			if prev, ok := c.last[cont.ID]; ok {
+				statsMap[cont.ID] = prev.stats
+				stale[cont.ID] = true
+				last[cont.ID] = prev
+			} else {
+				failed = append(failed, errs[i])
+			}
+		default:
+			failed = append(failed, errs[i])
+		}
+	}
+	// Stopped and removed containers drop out of the last-known stats
+	c.last = last
+
+	return statsMap, stale, failed
+}
+
+// containerStats fetches the stats of one container within the per-container deadline
+func (c *statsCollector) containerStats(ctx context.Context, cont docker.ContainerInfo) (*docker.ContainerStats, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	c.logger.Debug("[STATS] Fetching stats for container",
+		zap.String("name", cont.Name))
+	stats, err := c.client.GetContainerStats(ctx, cont)
+	if err != nil {
+		c.logger.Error("[ERROR] Failed to get container stats",
+			zap.String("container", cont.Name),
+			zap.String("id", cont.ID),
+			zap.Error(err))
+		return nil, err
+	}
+	c.logger.Debug("[STATS] Stats retrieved successfully",
+		zap.String("name", cont.Name),
+		zap.Float64("cpu_percent", stats.CPUPercent),
+		zap.Uint64("memory_usage", stats.MemoryUsage))
+	return stats, nil
+}
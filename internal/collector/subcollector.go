@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// subCollector is a named part of the collection that can be switched on or off
+// with --collector.<name> and --no-collector.<name>
+type subCollector interface {
+	Name() string
+	Describe(ch chan<- *prometheus.Desc)
+	// Update sends the metrics of one collection. containers is the container list
+	// shared by all sub-collectors of the collection, nil when it could not be fetched.
+	Update(ctx context.Context, containers []docker.ContainerInfo, ch chan<- prometheus.Metric) error
+}
+
+// subCollectorFactory creates a sub-collector for a Collector with its configuration
+type subCollectorFactory func(c *Collector, cfg *config.Config) subCollector
+
+// subCollectorFactories holds the registered sub-collectors by name
+var subCollectorFactories = make(map[string]subCollectorFactory)
+
+// registerSubCollector makes a sub-collector available under name and declares its
+// flags. It must be called from init.
+func registerSubCollector(name string, enabledByDefault bool, factory subCollectorFactory) {
+	subCollectorFactories[name] = factory
+	config.RegisterCollector(name, enabledByDefault)
+}
+
+// newSubCollectors creates the sub-collectors enabled in cfg, sorted by name
+func newSubCollectors(c *Collector, cfg *config.Config) []subCollector {
+	var subs []subCollector
+	for _, name := range config.CollectorNames() {
+		if cfg.CollectorEnabled(name) {
+			subs = append(subs, subCollectorFactories[name](c, cfg))
+		}
+	}
+	return subs
+}
+
+// updateSubCollectors runs all sub-collectors concurrently and returns their metrics
+// and results. A failing sub-collector is logged and keeps the metrics it sent before failing.
+func (c *Collector) updateSubCollectors(ctx context.Context, containers []docker.ContainerInfo) ([]prometheus.Metric, []collectorResult) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		metrics []prometheus.Metric
+		results = make([]collectorResult, len(c.subCollectors))
+	)
+
+	for i, sub := range c.subCollectors {
+		wg.Add(1)
+		go func(i int, sub subCollector) {
+			defer wg.Done()
+
+			start := time.Now()
+			subMetrics, err := collectMetrics(ctx, sub, containers)
+			results[i] = c.result(sub.Name(), start, err)
+			if err != nil {
+				c.logger.Error("Sub-collector failed",
+					zap.String("collector", sub.Name()),
+					zap.Error(err))
+			}
+
+			mu.Lock()
+			metrics = append(metrics, subMetrics...)
+			mu.Unlock()
+		}(i, sub)
+	}
+
+	wg.Wait()
+	return metrics, results
+}
+
+// collectMetrics runs one sub-collector and buffers its metrics
+func collectMetrics(ctx context.Context, sub subCollector, containers []docker.ContainerInfo) ([]prometheus.Metric, error) {
+	ch := make(chan prometheus.Metric, 64)
+	done := make(chan struct{})
+
+	var metrics []prometheus.Metric
+	go func() {
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		close(done)
+	}()
+
+	err := sub.Update(ctx, containers, ch)
+	close(ch)
+	<-done
+
+	return metrics, err
+}
@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/nhattuanbl/docker-exporter/internal/config"
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerSubCollector("swarm", false, func(c *Collector, cfg *config.Config) subCollector {
+		return newSwarmCollector(c.client, c.prefix)
+	})
+}
+
+// swarmCollector exports swarm services, tasks and nodes. Only managers serve
+// this data, so on other daemons it reports swarm_manager 0 and nothing else.
+type swarmCollector struct {
+	client *docker.Client
+
+	manager          *prometheus.Desc
+	serviceInfo      *prometheus.Desc
+	serviceDesired   *prometheus.Desc
+	serviceRunning   *prometheus.Desc
+	serviceTasks     *prometheus.Desc
+	nodeInfo         *prometheus.Desc
+	managerReachable *prometheus.Desc
+	managerLeader    *prometheus.Desc
+}
+
+// newSwarmCollector creates a new swarmCollector
+func newSwarmCollector(client *docker.Client, prefix string) *swarmCollector {
+	return &swarmCollector{
+		client: client,
+
+		manager: prometheus.NewDesc(
+			prefix+"_swarm_manager",
+			"Whether the daemon is a swarm manager (1=manager, 0=worker or swarm inactive)",
+			nil, nil,
+		),
+		serviceInfo: prometheus.NewDesc(
+			prefix+"_swarm_service_info",
+			"Swarm service information",
+			[]string{"id", "service", "mode"}, nil,
+		),
+		serviceDesired: prometheus.NewDesc(
+			prefix+"_swarm_service_replicas_desired",
+			"Number of tasks the service should be running",
+			[]string{"service"}, nil,
+		),
+		serviceRunning: prometheus.NewDesc(
+			prefix+"_swarm_service_replicas_running",
+			"Number of tasks of the service in the running state",
+			[]string{"service"}, nil,
+		),
+		serviceTasks: prometheus.NewDesc(
+			prefix+"_swarm_service_tasks",
+			"Number of tasks of the service by state, task history included",
+			[]string{"service", "state"}, nil,
+		),
+		nodeInfo: prometheus.NewDesc(
+			prefix+"_swarm_node_info",
+			"Swarm node information",
+			[]string{"id", "hostname", "role", "availability", "status"}, nil,
+		),
+		managerReachable: prometheus.NewDesc(
+			prefix+"_swarm_manager_reachable",
+			"Whether the swarm manager is reachable (1=reachable, 0=unreachable or unknown)",
+			[]string{"id", "hostname"}, nil,
+		),
+		managerLeader: prometheus.NewDesc(
+			prefix+"_swarm_manager_leader",
+			"Whether the swarm manager is the raft leader (1=leader, 0=follower)",
+			[]string{"id", "hostname"}, nil,
+		),
+	}
+}
+
+// Name implements subCollector
+func (c *swarmCollector) Name() string {
+	return "swarm"
+}
+
+// Describe implements subCollector
+func (c *swarmCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.manager
+	ch <- c.serviceInfo
+	ch <- c.serviceDesired
+	ch <- c.serviceRunning
+	ch <- c.serviceTasks
+	ch <- c.nodeInfo
+	ch <- c.managerReachable
+	ch <- c.managerLeader
+}
+
+// Update implements subCollector
+func (c *swarmCollector) Update(ctx context.Context, _ []docker.ContainerInfo, ch chan<- prometheus.Metric) error {
+	manager, err := c.client.SwarmManager(ctx)
+	if err != nil {
+		return err
+	}
+	if !manager {
+		ch <- prometheus.MustNewConstMetric(c.manager, prometheus.GaugeValue, 0)
+		return nil
+	}
+
+	services, err := c.client.GetServices(ctx)
+	if err != nil {
+		return err
+	}
+	nodes, err := c.client.GetNodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.manager, prometheus.GaugeValue, 1)
+
+	for _, svc := range services {
+		ch <- prometheus.MustNewConstMetric(
+			c.serviceInfo, prometheus.GaugeValue, 1,
+			svc.ID, svc.Name, svc.Mode,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.serviceDesired, prometheus.GaugeValue, float64(svc.Desired),
+			svc.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.serviceRunning, prometheus.GaugeValue, float64(svc.Running),
+			svc.Name,
+		)
+		// Every state is exported so series do not disappear when a count drops to zero
+		for _, state := range docker.TaskStates {
+			ch <- prometheus.MustNewConstMetric(
+				c.serviceTasks, prometheus.GaugeValue, float64(svc.Tasks[state]),
+				svc.Name, state,
+			)
+		}
+	}
+
+	for _, node := range nodes {
+		ch <- prometheus.MustNewConstMetric(
+			c.nodeInfo, prometheus.GaugeValue, 1,
+			node.ID, node.Hostname, node.Role, node.Availability, node.Status,
+		)
+		if !node.Manager {
+			continue
+		}
+
+		var reachable, leader float64
+		if node.Reachable {
+			reachable = 1
+		}
+		if node.Leader {
+			leader = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.managerReachable, prometheus.GaugeValue, reachable,
+			node.ID, node.Hostname,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.managerLeader, prometheus.GaugeValue, leader,
+			node.ID, node.Hostname,
+		)
+	}
+
+	return nil
+}
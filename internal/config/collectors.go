@@ -0,0 +1,90 @@
+package config
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/spf13/pflag"
+)
+
+// collectorDefaults holds the registered sub-collectors and whether they run by default.
+// It is only written from init functions, before any goroutine reads it.
+var collectorDefaults = make(map[string]bool)
+
+// RegisterCollector declares a sub-collector so it gets --collector.<name> and
+// --no-collector.<name> flags. It must be called before Parse, typically from init.
+func RegisterCollector(name string, enabledByDefault bool) {
+	collectorDefaults[name] = enabledByDefault
+}
+
+// CollectorNames returns the registered sub-collector names, sorted
+func CollectorNames() []string {
+	names := make([]string, 0, len(collectorDefaults))
+	for name := range collectorDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// knownCollector reports whether a sub-collector is registered
+func knownCollector(name string) bool {
+	_, ok := collectorDefaults[name]
+	return ok
+}
+
+// CollectorEnabled reports whether a sub-collector runs, from the collectors setting
+// or else its registered default
+func (c *Config) CollectorEnabled(name string) bool {
+	if enabled, ok := c.Collectors[name]; ok {
+		return enabled
+	}
+	return collectorDefaults[name]
+}
+
+// collectorFlag is a boolean flag that enables (--collector.<name>) or
+// disables (--no-collector.<name>) a sub-collector in Config.Collectors
+type collectorFlag struct {
+	cfg    *Config
+	name   string
+	enable bool
+}
+
+// String implements pflag.Value
+func (f *collectorFlag) String() string {
+	if !f.enable {
+		return "false"
+	}
+	return strconv.FormatBool(f.cfg.CollectorEnabled(f.name))
+}
+
+// Set implements pflag.Value
+func (f *collectorFlag) Set(value string) error {
+	set, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if f.cfg.Collectors == nil {
+		f.cfg.Collectors = make(map[string]bool)
+	}
+	f.cfg.Collectors[f.name] = set == f.enable
+	return nil
+}
+
+// Type implements pflag.Value
+func (f *collectorFlag) Type() string {
+	return "bool"
+}
+
+// bindCollectorFlags registers the enable and disable flags of every sub-collector
+func bindCollectorFlags(fs *pflag.FlagSet, cfg *Config) {
+	for _, name := range CollectorNames() {
+		enable := fs.VarPF(&collectorFlag{cfg: cfg, name: name, enable: true},
+			"collector."+name, "", "Enable the "+name+" collector")
+		enable.NoOptDefVal = "true"
+
+		disable := fs.VarPF(&collectorFlag{cfg: cfg, name: name, enable: false},
+			"no-collector."+name, "", "Disable the "+name+" collector")
+		disable.NoOptDefVal = "true"
+	}
+}
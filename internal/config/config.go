@@ -1,11 +1,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/nhattuanbl/docker-exporter/internal/docker"
 	"github.com/spf13/pflag"
 )
 
@@ -19,36 +24,197 @@ var (
 
 // Config holds the application configuration
 type Config struct {
-	Host       string
-	Port       int
-	Endpoint   string
-	Prefix     string
-	LogLevel   string
-	LogPath    string
-	DockerHost string
-	OutputMode string        // "minimum" or "all"
-	Timeout    time.Duration // API request timeout
-}
-
-// Parse parses command line flags and returns the configuration
-func Parse() (*Config, bool) {
-	cfg := &Config{}
+	Host       string        `yaml:"host"`
+	Port       int           `yaml:"port"`
+	Endpoint   string        `yaml:"endpoint"`
+	Prefix     string        `yaml:"prefix"`
+	LogLevel   string        `yaml:"log_level"`
+	LogPath    string        `yaml:"log_path"`
+	DockerHost string        `yaml:"docker_host"`
+	OutputMode string        `yaml:"output"`  // "minimum" or "all"
+	Timeout    time.Duration `yaml:"timeout"` // API request timeout
+
+	// Docker daemon TLS
+	DockerTLSCA     string `yaml:"docker_tls_ca"`
+	DockerTLSCert   string `yaml:"docker_tls_cert"`
+	DockerTLSKey    string `yaml:"docker_tls_key"`
+	DockerTLSVerify bool   `yaml:"docker_tls_verify"`
+
+	// Docker labels exported as Prometheus labels (glob patterns)
+	ContainerLabels []string `yaml:"container_labels"`
+
+	// Container filters (selector expressions, see docker.ParseSelector)
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+
+	// Background collection interval (0 collects on every scrape)
+	CollectInterval time.Duration `yaml:"collect_interval"`
+
+	// Maintain the container inventory from the Docker events stream
+	Events bool `yaml:"events"`
+
+	// Refresh interval of the expensive /system/df call (0, the default, disables it)
+	DiskUsageInterval time.Duration `yaml:"disk_usage_interval"`
+
+	// Concurrent inspect calls when listing containers, and whether containers are
+	// inspected at all (false builds them from the list summaries)
+	InspectConcurrency int  `yaml:"inspect_concurrency"`
+	ContainerInspect   bool `yaml:"container_inspect"`
+
+	// Where container stats are read: "api" or "cgroup" (the cgroup filesystem at
+	// CgroupRoot, for a daemon on the same host). The container cgroups are found
+	// through the host /proc at ProcRoot.
+	StatsSource string `yaml:"stats_source"`
+	CgroupRoot  string `yaml:"cgroup_root"`
+	ProcRoot    string `yaml:"proc_root"`
+
+	// Concurrent stats calls and the deadline of each call (0 uses the request timeout)
+	StatsConcurrency int           `yaml:"stats_concurrency"`
+	StatsTimeout     time.Duration `yaml:"stats_timeout"`
+
+	// Sub-collectors enabled or disabled by name, overriding their defaults
+	Collectors map[string]bool `yaml:"collectors"`
+
+	// Named Docker daemons to collect from (config file only). When empty the
+	// exporter collects from DockerHost with the docker_tls_* settings.
+	Daemons []DaemonConfig `yaml:"daemons"`
+
+	// Serve /probe, and the targets the implicit default module may probe
+	// (regular expressions matched against the whole target)
+	Probe        bool     `yaml:"probe"`
+	ProbeTargets []string `yaml:"probe_targets"`
+
+	// Modules selectable with /probe?module=<name> (config file only)
+	Modules map[string]ModuleConfig `yaml:"modules"`
+}
+
+// ModuleConfig holds the settings /probe applies to a target
+type ModuleConfig struct {
+	Timeout    time.Duration   `yaml:"timeout"` // 0 uses the global timeout
+	TLSCA      string          `yaml:"tls_ca"`
+	TLSCert    string          `yaml:"tls_cert"`
+	TLSKey     string          `yaml:"tls_key"`
+	TLSVerify  bool            `yaml:"tls_verify"`
+	Collectors map[string]bool `yaml:"collectors"` // merged over the global collectors
+	Targets    []string        `yaml:"targets"`    // regular expressions of the targets the module may probe
+}
+
+// DaemonConfig holds the connection settings of one Docker daemon
+type DaemonConfig struct {
+	Name      string        `yaml:"name"` // value of the docker_host label
+	Host      string        `yaml:"host"`
+	Timeout   time.Duration `yaml:"timeout"` // 0 uses the global timeout
+	TLSCA     string        `yaml:"tls_ca"`
+	TLSCert   string        `yaml:"tls_cert"`
+	TLSKey    string        `yaml:"tls_key"`
+	TLSVerify bool          `yaml:"tls_verify"`
+}
+
+// TLSEnabled reports whether TLS is configured for the daemon
+func (d DaemonConfig) TLSEnabled() bool {
+	return d.TLSVerify || d.TLSCA != "" || d.TLSCert != "" || d.TLSKey != ""
+}
+
+// cliFlags holds the command line options that are not part of Config
+type cliFlags struct {
+	showVersion bool
+	configFile  string
+	configCheck bool
+}
+
+// defaultConfig returns the configuration used when neither a flag nor the config file sets a value
+func defaultConfig() *Config {
+	cfg := &Config{
+		Host:       "0.0.0.0",
+		Port:       9324,
+		Endpoint:   "metrics",
+		Prefix:     "ndocker",
+		LogLevel:   "info",
+		DockerHost: "tcp://localhost:2375",
+		OutputMode: "minimum",
+		Timeout:    2 * time.Second,
 
-	pflag.StringVarP(&cfg.Host, "host", "h", "0.0.0.0", "Bind address")
-	pflag.IntVarP(&cfg.Port, "port", "p", 9324, "Port number")
-	pflag.StringVarP(&cfg.Endpoint, "endpoint", "e", "metrics", "Metrics endpoint path")
-	pflag.StringVarP(&cfg.Prefix, "prefix", "r", "ndocker", "Metric name prefix")
-	pflag.StringVarP(&cfg.LogLevel, "log-level", "l", "info", "Log level: debug, info, warn, error")
-	pflag.StringVarP(&cfg.LogPath, "log-path", "o", "", "Log file path (default stdout only)")
-	pflag.StringVarP(&cfg.DockerHost, "docker-host", "d", "tcp://localhost:2375", "Docker daemon address")
-	pflag.StringVarP(&cfg.OutputMode, "output", "u", "minimum", "Output mode: minimum (only ndocker_*) or all (include go_*, process_*, promhttp_*)")
-	pflag.DurationVarP(&cfg.Timeout, "timeout", "t", 2*time.Second, "Timeout for Docker API requests")
+		StatsConcurrency: 16,
+		StatsSource:      "api",
+		CgroupRoot:       "/sys/fs/cgroup",
+		ProcRoot:         "/proc",
 
-	showVersion := pflag.BoolP("version", "v", false, "Show version information")
+		InspectConcurrency: 16,
+		ContainerInspect:   true,
+	}
+
+	return cfg
+}
+
+// applyTLSEnv fills the TLS settings left unset from the Docker CLI environment
+// variables. Like the Docker CLI it only does so for tcp:// hosts, since unix sockets
+// never use TLS.
+func (c *Config) applyTLSEnv() {
+	if !strings.HasPrefix(c.DockerHost, "tcp://") {
+		return
+	}
+	if certPath := os.Getenv("DOCKER_CERT_PATH"); certPath != "" {
+		if c.DockerTLSCA == "" {
+			c.DockerTLSCA = filepath.Join(certPath, "ca.pem")
+		}
+		if c.DockerTLSCert == "" && c.DockerTLSKey == "" {
+			c.DockerTLSCert = filepath.Join(certPath, "cert.pem")
+			c.DockerTLSKey = filepath.Join(certPath, "key.pem")
+		}
+	}
+	if os.Getenv("DOCKER_TLS_VERIFY") != "" {
+		c.DockerTLSVerify = true
+	}
+}
+
+// bindFlags registers all flags on fs, using the current values of cfg as defaults
+func bindFlags(fs *pflag.FlagSet, cfg *Config, cli *cliFlags) {
+	fs.StringVarP(&cfg.Host, "host", "h", cfg.Host, "Bind address")
+	fs.IntVarP(&cfg.Port, "port", "p", cfg.Port, "Port number")
+	fs.StringVarP(&cfg.Endpoint, "endpoint", "e", cfg.Endpoint, "Metrics endpoint path")
+	fs.StringVarP(&cfg.Prefix, "prefix", "r", cfg.Prefix, "Metric name prefix")
+	fs.StringVarP(&cfg.LogLevel, "log-level", "l", cfg.LogLevel, "Log level: debug, info, warn, error")
+	fs.StringVarP(&cfg.LogPath, "log-path", "o", cfg.LogPath, "Log file path (default stdout only)")
+	fs.StringVarP(&cfg.DockerHost, "docker-host", "d", cfg.DockerHost, "Docker daemon address")
+	fs.StringVarP(&cfg.OutputMode, "output", "u", cfg.OutputMode, "Output mode: minimum (only ndocker_*) or all (include go_*, process_*, promhttp_*)")
+	fs.DurationVarP(&cfg.Timeout, "timeout", "t", cfg.Timeout, "Timeout for Docker API requests")
+
+	fs.StringVar(&cfg.DockerTLSCA, "docker-tls-ca", cfg.DockerTLSCA, "CA certificate used to verify the Docker daemon")
+	fs.StringVar(&cfg.DockerTLSCert, "docker-tls-cert", cfg.DockerTLSCert, "Client certificate for the Docker daemon")
+	fs.StringVar(&cfg.DockerTLSKey, "docker-tls-key", cfg.DockerTLSKey, "Client key for the Docker daemon")
+	fs.BoolVar(&cfg.DockerTLSVerify, "docker-tls-verify", cfg.DockerTLSVerify, "Verify the Docker daemon certificate")
+
+	fs.StringSliceVar(&cfg.ContainerLabels, "container-label", cfg.ContainerLabels, "Docker label to export on the container labels metric, glob patterns allowed (repeatable)")
+	fs.StringArrayVar(&cfg.Include, "include", cfg.Include, "Only collect containers matching name=<regex>, image=<glob>, label=<key>[=<value>] or state=<state> (repeatable)")
+	fs.StringArrayVar(&cfg.Exclude, "exclude", cfg.Exclude, "Skip containers matching name=<regex>, image=<glob>, label=<key>[=<value>] or state=<state> (repeatable)")
+	fs.DurationVar(&cfg.CollectInterval, "collect-interval", cfg.CollectInterval, "Collect in the background at this interval and serve the cached snapshot on scrape (0 collects on every scrape)")
+	fs.BoolVar(&cfg.Events, "events", cfg.Events, "Keep the container inventory up to date from the Docker events stream instead of listing containers on every collection")
+	fs.DurationVar(&cfg.DiskUsageInterval, "disk-usage-interval", cfg.DiskUsageInterval, "Refresh interval of the Docker disk usage (df) data, 0 disables it")
+	fs.IntVar(&cfg.InspectConcurrency, "inspect-concurrency", cfg.InspectConcurrency, "Maximum number of concurrent container inspect calls")
+	fs.BoolVar(&cfg.ContainerInspect, "container-inspect", cfg.ContainerInspect, "Inspect every container; false builds containers from the list summaries, without start time, restart count, OOM flag and CPU limits")
+	fs.StringVar(&cfg.StatsSource, "stats-source", cfg.StatsSource, "Source of container stats: api (Docker stats API) or cgroup (cgroup filesystem of a local unix:// Docker daemon)")
+	fs.StringVar(&cfg.CgroupRoot, "cgroup-root", cfg.CgroupRoot, "Mount point of the host cgroup filesystem for --stats-source=cgroup")
+	fs.StringVar(&cfg.ProcRoot, "proc-root", cfg.ProcRoot, "Mount point of the host /proc, used by --stats-source=cgroup to find the cgroup of each container")
+	fs.IntVar(&cfg.StatsConcurrency, "stats-concurrency", cfg.StatsConcurrency, "Maximum number of concurrent container stats calls")
+	fs.DurationVar(&cfg.StatsTimeout, "stats-timeout", cfg.StatsTimeout, "Deadline of each container stats call, containers over it keep their last-known stats (0 uses --timeout)")
+	fs.BoolVar(&cfg.Probe, "probe", cfg.Probe, "Serve the /probe endpoint for targets chosen by Prometheus service discovery")
+	fs.StringArrayVar(&cfg.ProbeTargets, "probe-target", cfg.ProbeTargets, "Regular expression of the targets the default /probe module may probe, matched against the whole target (repeatable)")
+	bindCollectorFlags(fs, cfg)
+
+	fs.StringVar(&cli.configFile, "config.file", cli.configFile, "Path to a YAML configuration file (flags override file values)")
+	fs.BoolVar(&cli.configCheck, "config.check", cli.configCheck, "Validate the configuration file and exit")
+	fs.BoolVarP(&cli.showVersion, "version", "v", cli.showVersion, "Show version information")
+}
+
+// Parse parses command line flags and the optional configuration file and returns the configuration
+func Parse() (*Config, bool) {
+	cfg := defaultConfig()
+	cli := &cliFlags{}
 
+	bindFlags(pflag.CommandLine, cfg, cli)
 	pflag.Parse()
 
-	if *showVersion {
+	if cli.showVersion {
 		fmt.Printf("docker-exporter %s\n", Version)
 		fmt.Printf("  Git Commit: %s\n", GitCommit)
 		fmt.Printf("  Build Date: %s\n", BuildDate)
@@ -56,17 +222,177 @@ func Parse() (*Config, bool) {
 		os.Exit(0)
 	}
 
+	if cli.configFile != "" {
+		fileCfg, err := LoadFile(cli.configFile)
+		if err != nil {
+			printErrors(cli.configFile, err)
+			return nil, false
+		}
+
+		// Flags override the file: parse the command line again with the file values as defaults
+		fs := pflag.NewFlagSet(os.Args[0], pflag.ExitOnError)
+		bindFlags(fs, fileCfg, cli)
+		fs.Parse(os.Args[1:])
+		cfg = fileCfg
+	} else if cli.configCheck {
+		fmt.Fprintln(os.Stderr, "--config.check requires --config.file")
+		return nil, false
+	}
+
+	cfg.applyTLSEnv()
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		printErrors("configuration", err)
+		return nil, false
+	}
+
+	if cli.configCheck {
+		fmt.Printf("%s: configuration is valid\n", cli.configFile)
+		os.Exit(0)
+	}
+
+	return cfg, true
+}
+
+// normalize canonicalizes values that are accepted in several spellings
+func (c *Config) normalize() {
 	// Normalize endpoint path
-	cfg.Endpoint = strings.TrimPrefix(cfg.Endpoint, "/")
-	cfg.LogLevel = strings.ToLower(cfg.LogLevel)
-	cfg.OutputMode = strings.ToLower(cfg.OutputMode)
+	c.Endpoint = strings.TrimPrefix(c.Endpoint, "/")
+	c.LogLevel = strings.ToLower(c.LogLevel)
+	c.OutputMode = strings.ToLower(c.OutputMode)
+	c.StatsSource = strings.ToLower(c.StatsSource)
 
 	// Validate output mode
-	if cfg.OutputMode != "minimum" && cfg.OutputMode != "all" {
-		cfg.OutputMode = "minimum"
+	if c.OutputMode != "minimum" && c.OutputMode != "all" {
+		c.OutputMode = "minimum"
 	}
+}
 
-	return cfg, true
+// Validate checks the configuration for invalid values
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fieldError("port", fmt.Errorf("must be between 1 and 65535, got %d", c.Port)))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fieldError("timeout", fmt.Errorf("must be positive, got %s", c.Timeout)))
+	}
+	if c.CollectInterval < 0 {
+		errs = append(errs, fieldError("collect_interval", fmt.Errorf("must not be negative, got %s", c.CollectInterval)))
+	}
+	if c.DiskUsageInterval < 0 {
+		errs = append(errs, fieldError("disk_usage_interval", fmt.Errorf("must not be negative, got %s", c.DiskUsageInterval)))
+	}
+	if c.DiskUsageInterval == 0 && c.CollectorEnabled("df") {
+		errs = append(errs, fieldError("collectors.df", errors.New("the df collector needs disk_usage_interval to be set")))
+	}
+	if c.StatsSource != "api" && c.StatsSource != "cgroup" {
+		errs = append(errs, fieldError("stats_source", fmt.Errorf("must be api or cgroup, got %q", c.StatsSource)))
+	}
+	// The cgroup filesystem only describes the containers of a daemon on this host
+	if c.StatsSource == "cgroup" {
+		for _, d := range c.DockerDaemons() {
+			if !strings.HasPrefix(d.Host, "unix://") {
+				errs = append(errs, fieldError("stats_source", fmt.Errorf("cgroup requires local unix:// daemons, got %s for %q", d.Host, d.Name)))
+			}
+		}
+	}
+	if c.InspectConcurrency < 1 {
+		errs = append(errs, fieldError("inspect_concurrency", fmt.Errorf("must be at least 1, got %d", c.InspectConcurrency)))
+	}
+	if c.StatsConcurrency < 1 {
+		errs = append(errs, fieldError("stats_concurrency", fmt.Errorf("must be at least 1, got %d", c.StatsConcurrency)))
+	}
+	if c.StatsTimeout < 0 {
+		errs = append(errs, fieldError("stats_timeout", fmt.Errorf("must not be negative, got %s", c.StatsTimeout)))
+	}
+	if (c.DockerTLSCert == "") != (c.DockerTLSKey == "") {
+		errs = append(errs, fieldError("docker_tls_cert", errors.New("client certificate and key must be set together")))
+	}
+	names := make(map[string]bool, len(c.Daemons))
+	for i, d := range c.Daemons {
+		switch {
+		case d.Name == "":
+			errs = append(errs, itemError("daemons", i, errors.New("name is required")))
+		case names[d.Name]:
+			errs = append(errs, itemError("daemons", i, fmt.Errorf("duplicate name %q", d.Name)))
+		}
+		names[d.Name] = true
+
+		if d.Host == "" {
+			errs = append(errs, itemError("daemons", i, errors.New("host is required")))
+		}
+		if d.Timeout < 0 {
+			errs = append(errs, itemError("daemons", i, fmt.Errorf("timeout must not be negative, got %s", d.Timeout)))
+		}
+		if (d.TLSCert == "") != (d.TLSKey == "") {
+			errs = append(errs, itemError("daemons", i, errors.New("client certificate and key must be set together")))
+		}
+	}
+	for name := range c.Collectors {
+		if !knownCollector(name) {
+			errs = append(errs, fieldError("collectors."+name, fmt.Errorf("unknown collector %q", name)))
+		}
+	}
+	for name, m := range c.Modules {
+		field := "modules." + name
+		for collector := range m.Collectors {
+			if !knownCollector(collector) {
+				errs = append(errs, fieldError(field+".collectors."+collector, fmt.Errorf("unknown collector %q", collector)))
+			}
+		}
+		if m.Timeout < 0 {
+			errs = append(errs, fieldError(field, fmt.Errorf("timeout must not be negative, got %s", m.Timeout)))
+		}
+		if (m.TLSCert == "") != (m.TLSKey == "") {
+			errs = append(errs, fieldError(field, errors.New("client certificate and key must be set together")))
+		}
+		if c.Probe && len(m.Targets) == 0 {
+			errs = append(errs, fieldError(field, errors.New("targets is required")))
+		}
+		for i, expr := range m.Targets {
+			if _, err := TargetPattern(expr); err != nil {
+				errs = append(errs, itemError(field+".targets", i, err))
+			}
+		}
+	}
+	if c.Probe && len(c.Modules) == 0 && len(c.ProbeTargets) == 0 {
+		errs = append(errs, fieldError("probe_targets", errors.New("at least one target pattern is required to enable probe")))
+	}
+	for i, expr := range c.ProbeTargets {
+		if _, err := TargetPattern(expr); err != nil {
+			errs = append(errs, itemError("probe_targets", i, err))
+		}
+	}
+	for i, pattern := range c.ContainerLabels {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, itemError("container_labels", i, fmt.Errorf("invalid pattern %q: %w", pattern, err)))
+		}
+	}
+	for i, expr := range c.Include {
+		if _, err := docker.ParseSelector(expr); err != nil {
+			errs = append(errs, itemError("include", i, err))
+		}
+	}
+	for i, expr := range c.Exclude {
+		if _, err := docker.ParseSelector(expr); err != nil {
+			errs = append(errs, itemError("exclude", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// printErrors writes each joined error on its own line to stderr
+func printErrors(source string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", source, e)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", source, err)
 }
 
 // Address returns the full bind address
@@ -74,6 +400,75 @@ func (c *Config) Address() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// MultiDaemon reports whether several named daemons are configured. Only then
+// do series carry the docker_host label.
+func (c *Config) MultiDaemon() bool {
+	return len(c.Daemons) > 0
+}
+
+// DockerDaemons returns the daemons to collect from, with the global timeout applied
+// where a daemon does not set its own. Without a daemons list it returns a single
+// daemon built from DockerHost and the docker_tls_* settings.
+func (c *Config) DockerDaemons() []DaemonConfig {
+	if !c.MultiDaemon() {
+		return []DaemonConfig{{
+			Name:      "default",
+			Host:      c.DockerHost,
+			Timeout:   c.Timeout,
+			TLSCA:     c.DockerTLSCA,
+			TLSCert:   c.DockerTLSCert,
+			TLSKey:    c.DockerTLSKey,
+			TLSVerify: c.DockerTLSVerify,
+		}}
+	}
+
+	daemons := make([]DaemonConfig, len(c.Daemons))
+	for i, d := range c.Daemons {
+		if d.Timeout == 0 {
+			d.Timeout = c.Timeout
+		}
+		daemons[i] = d
+	}
+	return daemons
+}
+
+// Module returns the /probe module with the given name, with the global timeout and
+// collectors applied. Unless configured otherwise, the "default" module probes the
+// probe_targets without TLS; the docker_tls_* settings are never sent to probe targets.
+func (c *Config) Module(name string) (ModuleConfig, bool) {
+	m, ok := c.Modules[name]
+	if !ok {
+		if name != "default" {
+			return ModuleConfig{}, false
+		}
+		m = ModuleConfig{Targets: c.ProbeTargets}
+	}
+
+	if m.Timeout == 0 {
+		m.Timeout = c.Timeout
+	}
+	collectors := make(map[string]bool, len(c.Collectors)+len(m.Collectors))
+	for name, enabled := range c.Collectors {
+		collectors[name] = enabled
+	}
+	for name, enabled := range m.Collectors {
+		collectors[name] = enabled
+	}
+	m.Collectors = collectors
+
+	return m, true
+}
+
+// TargetPattern compiles a /probe target pattern, anchored so that it must match the
+// whole target
+func TargetPattern(expr string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid target pattern %q: %w", expr, err)
+	}
+	return re, nil
+}
+
 // MetricsPath returns the metrics endpoint path with leading slash
 func (c *Config) MetricsPath() string {
 	return "/" + c.Endpoint
@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestApplyTLSEnv(t *testing.T) {
+	t.Setenv("DOCKER_CERT_PATH", "/certs")
+	t.Setenv("DOCKER_TLS_VERIFY", "1")
+
+	tests := []struct {
+		host   string
+		verify bool
+		ca     string
+	}{
+		{host: "unix:///var/run/docker.sock"},
+		{host: "tcp://docker:2376", verify: true, ca: "/certs/ca.pem"},
+	}
+	for _, tt := range tests {
+		cfg := defaultConfig()
+		cfg.DockerHost = tt.host
+		cfg.applyTLSEnv()
+
+		if cfg.DockerTLSVerify != tt.verify {
+			t.Errorf("%s: DockerTLSVerify = %v, want %v", tt.host, cfg.DockerTLSVerify, tt.verify)
+		}
+		if cfg.DockerTLSCA != tt.ca {
+			t.Errorf("%s: DockerTLSCA = %q, want %q", tt.host, cfg.DockerTLSCA, tt.ca)
+		}
+	}
+}
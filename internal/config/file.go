@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// FieldError describes an invalid configuration value
+type FieldError struct {
+	Field string // YAML key of the field
+	Index int    // Position in a list field, or -1
+	Line  int    // Line in the configuration file, or 0 when unknown
+	Err   error
+}
+
+// fieldError creates a FieldError for a whole field
+func fieldError(field string, err error) *FieldError {
+	return &FieldError{Field: field, Index: -1, Err: err}
+}
+
+// itemError creates a FieldError for a single item of a list field
+func itemError(field string, index int, err error) *FieldError {
+	return &FieldError{Field: field, Index: index, Err: err}
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	field := e.Field
+	if e.Index >= 0 {
+		field = fmt.Sprintf("%s[%d]", e.Field, e.Index)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %v", e.Line, field, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", field, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// LoadFile reads a YAML configuration file on top of the default configuration.
+// Unknown keys, type mismatches and invalid values are reported with their line numbers.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := defaultConfig()
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && err != io.EOF {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			errs := make([]error, 0, len(typeErr.Errors))
+			for _, msg := range typeErr.Errors {
+				errs = append(errs, errors.New(msg))
+			}
+			return nil, errors.Join(errs...)
+		}
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		annotateLines(err, &root)
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// annotateLines fills in the file line of every FieldError in err
+func annotateLines(err error, root *yaml.Node) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		var fieldErr *FieldError
+		if errors.As(e, &fieldErr) {
+			fieldErr.Line = lineOf(root, fieldErr.Field, fieldErr.Index)
+		}
+	}
+}
+
+// lineOf returns the line of a key, or of one of its list items when index >= 0.
+// Nested keys are separated by dots, e.g. modules.default.
+func lineOf(root *yaml.Node, field string, index int) int {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line := 0
+	for _, key := range strings.Split(field, ".") {
+		if node.Kind != yaml.MappingNode {
+			return line
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line = node.Content[i].Line
+				node = node.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return line
+		}
+	}
+
+	if index >= 0 && node.Kind == yaml.SequenceNode && index < len(node.Content) {
+		return node.Content[index].Line
+	}
+	return line
+}
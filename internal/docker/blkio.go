@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// sysDevBlock is where the kernel exposes block devices by major:minor
+const sysDevBlock = "/sys/dev/block"
+
+// BlockDeviceStats holds block I/O statistics for one device
+type BlockDeviceStats struct {
+	Major uint64
+	Minor uint64
+	Name  string // kernel device name, or major:minor when it cannot be resolved
+
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadOps    uint64
+	WriteOps   uint64
+
+	// Only reported by cgroup v1 with the CFQ scheduler (nanoseconds)
+	ServiceTime uint64
+	WaitTime    uint64
+}
+
+// ID returns the major:minor identifier of the device
+func (d *BlockDeviceStats) ID() string {
+	return fmt.Sprintf("%d:%d", d.Major, d.Minor)
+}
+
+// deviceNames caches successfully resolved device names by major:minor
+var deviceNames sync.Map
+
+// deviceName resolves the kernel name of a block device from the exporter's own
+// /sys/dev/block, which only describes the daemon's devices when both run on the same
+// host. It returns major:minor when the name cannot be resolved; failed lookups are
+// not cached, so a device that appears later is still resolved.
+func deviceName(major, minor uint64) string {
+	id := fmt.Sprintf("%d:%d", major, minor)
+	if name, ok := deviceNames.Load(id); ok {
+		return name.(string)
+	}
+
+	f, err := os.Open(filepath.Join(sysDevBlock, id, "uevent"))
+	if err != nil {
+		return id
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "DEVNAME="); ok && name != "" {
+			deviceNames.Store(id, name)
+			return name
+		}
+	}
+	return id
+}
+
+// blockDeviceStats groups the blkio entries by device. Operation names are matched
+// case-insensitively because cgroup v1 reports "Read" while cgroup v2 reports "read".
+// Device names are only resolved for a local daemon and are major:minor otherwise.
+func blockDeviceStats(blkio container.BlkioStats, local bool) map[string]*BlockDeviceStats {
+	devices := make(map[string]*BlockDeviceStats)
+	device := func(entry container.BlkioStatEntry) *BlockDeviceStats {
+		id := fmt.Sprintf("%d:%d", entry.Major, entry.Minor)
+		d, ok := devices[id]
+		if !ok {
+			d = &BlockDeviceStats{
+				Major: entry.Major,
+				Minor: entry.Minor,
+				Name:  id,
+			}
+			if local {
+				d.Name = deviceName(entry.Major, entry.Minor)
+			}
+			devices[id] = d
+		}
+		return d
+	}
+
+	for _, entry := range blkio.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			device(entry).ReadBytes += entry.Value
+		case "write":
+			device(entry).WriteBytes += entry.Value
+		}
+	}
+	for _, entry := range blkio.IoServicedRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			device(entry).ReadOps += entry.Value
+		case "write":
+			device(entry).WriteOps += entry.Value
+		}
+	}
+	for _, entry := range blkio.IoServiceTimeRecursive {
+		if strings.ToLower(entry.Op) == "total" {
+			device(entry).ServiceTime += entry.Value
+		}
+	}
+	for _, entry := range blkio.IoWaitTimeRecursive {
+		if strings.ToLower(entry.Op) == "total" {
+			device(entry).WaitTime += entry.Value
+		}
+	}
+
+	return devices
+}
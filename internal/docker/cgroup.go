@@ -0,0 +1,460 @@
+package docker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// cgroupSampleTTL is how long the previous CPU reading of a container is kept
+const cgroupSampleTTL = 10 * time.Minute
+
+// userHZ is the unit of the cgroup v1 cpuacct.stat times (1/100 s on Linux)
+const userHZ = 100
+
+// PressureStat is the total stall time of one resource from a cgroup v2 pressure file
+type PressureStat struct {
+	Resource string // cpu, memory or io
+	Kind     string // some (at least one task stalled) or full (all tasks stalled)
+	Total    uint64 // microseconds
+}
+
+// CgroupReader reads container stats straight from the cgroup filesystem of the
+// Docker host, in the format of the Docker stats API
+type CgroupReader struct {
+	root string
+	proc string
+	v2   bool
+
+	mu     sync.Mutex
+	prev   map[string]cgroupSample // by full container ID
+	swept  time.Time
+	memory func() uint64
+}
+
+// cgroupSample is the last reading of a container, kept for its CPU percentage
+type cgroupSample struct {
+	dir  string // cgroup directory relative to the root (v2) or to each controller (v1)
+	cpu  container.CPUStats
+	read time.Time
+}
+
+// NewCgroupReader creates a reader for the cgroup v1 or v2 filesystem mounted at root,
+// with the host /proc mounted at proc
+func NewCgroupReader(root, proc string) (*CgroupReader, error) {
+	r := &CgroupReader{
+		root:   root,
+		proc:   proc,
+		prev:   make(map[string]cgroupSample),
+		memory: sync.OnceValue(func() uint64 { return hostMemory(proc) }),
+	}
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		r.v2 = true
+		return r, nil
+	}
+	if _, err := os.Stat(filepath.Join(root, "memory")); err == nil {
+		return r, nil
+	}
+	return nil, fmt.Errorf("cgroup: no cgroup v1 or v2 filesystem at %s", root)
+}
+
+// Stats reads the stats of a container by its full ID and the host PID of its main
+// process (0 when unknown). The CPU percentage is computed against the previous reading
+// of the container and relative to one CPU, as in the Docker API; it is 0 on the first
+// reading. Network counters come from the network namespace of the process, and only
+// when network is set, i.e. the namespace belongs to the container alone.
+func (r *CgroupReader) Stats(id string, pid int, network bool) (*container.StatsResponse, []PressureStat, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	prev, seen := r.prev[id]
+	r.mu.Unlock()
+
+	dir := prev.dir
+	if dir == "" {
+		var err error
+		if dir, err = r.containerDir(id, pid); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	stats := &container.StatsResponse{Read: now}
+	var (
+		pressure []PressureStat
+		err      error
+	)
+	if r.v2 {
+		pressure, err = r.readV2(filepath.Join(r.root, dir), stats)
+	} else {
+		err = r.readV1(dir, stats)
+	}
+	if err != nil {
+		r.mu.Lock()
+		delete(r.prev, id)
+		r.mu.Unlock()
+		return nil, nil, fmt.Errorf("cgroup: container %s: %w", shortID(id), err)
+	}
+
+	// Interfaces are not accounted in cgroups; a process that exited reads as none
+	if network && pid > 0 {
+		stats.Networks, _ = readNetDev(filepath.Join(r.proc, strconv.Itoa(pid), "net", "dev"))
+	}
+
+	// The wall clock stands in for the host CPU time of one CPU
+	stats.CPUStats.SystemUsage = uint64(now.UnixNano())
+	stats.CPUStats.OnlineCPUs = 1
+	stats.PreCPUStats = stats.CPUStats
+	if seen {
+		stats.PreCPUStats = prev.cpu
+	}
+
+	r.mu.Lock()
+	r.prev[id] = cgroupSample{dir: dir, cpu: stats.CPUStats, read: now}
+	if now.Sub(r.swept) > cgroupSampleTTL {
+		for key, sample := range r.prev {
+			if now.Sub(sample.read) > cgroupSampleTTL {
+				delete(r.prev, key)
+			}
+		}
+		r.swept = now
+	}
+	r.mu.Unlock()
+
+	return stats, pressure, nil
+}
+
+// containerDir finds the cgroup of a container from the /proc/<pid>/cgroup file of its
+// main process, which covers --cgroup-parent and rootless daemons. Without a PID, or
+// when that cgroup is not under the root, it falls back to the default locations of
+// the systemd and cgroupfs drivers.
+func (r *CgroupReader) containerDir(id string, pid int) (string, error) {
+	base := r.root
+	if !r.v2 {
+		base = filepath.Join(r.root, "memory")
+	}
+	if dir, ok := r.processDir(pid); ok {
+		if _, err := os.Stat(filepath.Join(base, dir)); err == nil {
+			return dir, nil
+		}
+	}
+	for _, dir := range []string{
+		filepath.Join("system.slice", "docker-"+id+".scope"),
+		filepath.Join("docker", id),
+	} {
+		if _, err := os.Stat(filepath.Join(base, dir)); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("cgroup: container %s not found under %s", shortID(id), r.root)
+}
+
+// processDir returns the cgroup of a process relative to the root, from the unified
+// hierarchy on cgroup v2 and the memory controller on cgroup v1. The root cgroup and
+// paths outside the cgroup namespace of the exporter ("/..") are not usable.
+func (r *CgroupReader) processDir(pid int) (string, bool) {
+	if pid <= 0 {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(r.proc, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", false
+	}
+
+	// Lines are hierarchy-ID:controller-list:cgroup-path
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if r.v2 {
+			if parts[0] != "0" || parts[1] != "" {
+				continue
+			}
+		} else if !slices.Contains(strings.Split(parts[1], ","), "memory") {
+			continue
+		}
+		dir := strings.TrimPrefix(parts[2], "/")
+		if dir == "" || strings.HasPrefix(dir, "..") {
+			return "", false
+		}
+		return dir, true
+	}
+	return "", false
+}
+
+// readV2 reads a cgroup v2 directory
+func (r *CgroupReader) readV2(dir string, stats *container.StatsResponse) ([]PressureStat, error) {
+	// CPU
+	cpu, err := readKeyedFile(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	stats.CPUStats.CPUUsage.TotalUsage = cpu["usage_usec"] * 1000
+	stats.CPUStats.CPUUsage.UsageInUsermode = cpu["user_usec"] * 1000
+	stats.CPUStats.CPUUsage.UsageInKernelmode = cpu["system_usec"] * 1000
+	stats.CPUStats.ThrottlingData.Periods = cpu["nr_periods"]
+	stats.CPUStats.ThrottlingData.ThrottledPeriods = cpu["nr_throttled"]
+	stats.CPUStats.ThrottlingData.ThrottledTime = cpu["throttled_usec"] * 1000
+
+	// Memory
+	if stats.MemoryStats.Usage, err = readCgroupValue(filepath.Join(dir, "memory.current")); err != nil {
+		return nil, err
+	}
+	if stats.MemoryStats.Stats, err = readKeyedFile(filepath.Join(dir, "memory.stat")); err != nil {
+		return nil, err
+	}
+	stats.MemoryStats.Limit = r.memoryLimit(filepath.Join(dir, "memory.max"))
+	// memory.stat has no swap usage on cgroup v2; report it under the cgroup v1 key
+	if swap, err := readCgroupValue(filepath.Join(dir, "memory.swap.current")); err == nil {
+		stats.MemoryStats.Stats["swap"] = swap
+	}
+
+	// Block I/O, with the entry names of the Docker API
+	io, err := os.ReadFile(filepath.Join(dir, "io.stat"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(io), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		var major, minor uint64
+		if _, err := fmt.Sscanf(fields[0], "%d:%d", &major, &minor); err != nil {
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			entry := container.BlkioStatEntry{Major: major, Minor: minor, Value: v}
+			switch key {
+			case "rbytes":
+				entry.Op = "read"
+				stats.BlkioStats.IoServiceBytesRecursive = append(stats.BlkioStats.IoServiceBytesRecursive, entry)
+			case "wbytes":
+				entry.Op = "write"
+				stats.BlkioStats.IoServiceBytesRecursive = append(stats.BlkioStats.IoServiceBytesRecursive, entry)
+			case "rios":
+				entry.Op = "read"
+				stats.BlkioStats.IoServicedRecursive = append(stats.BlkioStats.IoServicedRecursive, entry)
+			case "wios":
+				entry.Op = "write"
+				stats.BlkioStats.IoServicedRecursive = append(stats.BlkioStats.IoServicedRecursive, entry)
+			}
+		}
+	}
+
+	// PIDs
+	stats.PidsStats.Current, _ = readCgroupValue(filepath.Join(dir, "pids.current"))
+	stats.PidsStats.Limit, _ = readCgroupValue(filepath.Join(dir, "pids.max"))
+
+	// Pressure stall information, missing when the kernel has PSI disabled
+	var pressure []PressureStat
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		pressure = append(pressure, readPressure(filepath.Join(dir, resource+".pressure"), resource)...)
+	}
+
+	return pressure, nil
+}
+
+// readV1 reads the cgroup v1 controllers of a container directory
+func (r *CgroupReader) readV1(dir string, stats *container.StatsResponse) error {
+	controller := func(name, file string) string {
+		return filepath.Join(r.root, name, dir, file)
+	}
+
+	// CPU
+	var err error
+	if stats.CPUStats.CPUUsage.TotalUsage, err = readCgroupValue(controller("cpuacct", "cpuacct.usage")); err != nil {
+		return err
+	}
+	if acct, err := readKeyedFile(controller("cpuacct", "cpuacct.stat")); err == nil {
+		stats.CPUStats.CPUUsage.UsageInUsermode = acct["user"] * (1e9 / userHZ)
+		stats.CPUStats.CPUUsage.UsageInKernelmode = acct["system"] * (1e9 / userHZ)
+	}
+	if cpu, err := readKeyedFile(controller("cpu", "cpu.stat")); err == nil {
+		stats.CPUStats.ThrottlingData.Periods = cpu["nr_periods"]
+		stats.CPUStats.ThrottlingData.ThrottledPeriods = cpu["nr_throttled"]
+		stats.CPUStats.ThrottlingData.ThrottledTime = cpu["throttled_time"]
+	}
+
+	// Memory
+	if stats.MemoryStats.Usage, err = readCgroupValue(controller("memory", "memory.usage_in_bytes")); err != nil {
+		return err
+	}
+	if stats.MemoryStats.Stats, err = readKeyedFile(controller("memory", "memory.stat")); err != nil {
+		return err
+	}
+	stats.MemoryStats.Limit = r.memoryLimit(controller("memory", "memory.limit_in_bytes"))
+
+	// Block I/O
+	stats.BlkioStats.IoServiceBytesRecursive = readBlkioV1(controller("blkio", "blkio.throttle.io_service_bytes_recursive"))
+	stats.BlkioStats.IoServicedRecursive = readBlkioV1(controller("blkio", "blkio.throttle.io_serviced_recursive"))
+	stats.BlkioStats.IoServiceTimeRecursive = readBlkioV1(controller("blkio", "blkio.io_service_time_recursive"))
+	stats.BlkioStats.IoWaitTimeRecursive = readBlkioV1(controller("blkio", "blkio.io_wait_time_recursive"))
+
+	// PIDs
+	stats.PidsStats.Current, _ = readCgroupValue(controller("pids", "pids.current"))
+	stats.PidsStats.Limit, _ = readCgroupValue(controller("pids", "pids.max"))
+
+	return nil
+}
+
+// memoryLimit reads a memory limit file. Like the Docker API it reports the host
+// memory for containers without a limit.
+func (r *CgroupReader) memoryLimit(path string) uint64 {
+	limit, err := readCgroupValue(path)
+	// cgroup v1 reports no limit as the largest page-aligned int64
+	if err != nil || limit >= math.MaxInt64/2 {
+		return r.memory()
+	}
+	return limit
+}
+
+// hostMemory returns the total memory of the host from meminfo under proc, or 0
+func hostMemory(proc string) uint64 {
+	meminfo, err := readKeyedFile(filepath.Join(proc, "meminfo"))
+	if err != nil {
+		return 0
+	}
+	return meminfo["MemTotal:"] * 1024
+}
+
+// readCgroupValue reads a file holding a single number. "max" reads as math.MaxUint64.
+func readCgroupValue(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return math.MaxUint64, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readKeyedFile reads a file of "key value" lines, such as cpu.stat and memory.stat.
+// Lines whose value is not a number are skipped.
+func readKeyedFile(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values, scanner.Err()
+}
+
+// readBlkioV1 reads a cgroup v1 blkio file of "major:minor op value" lines. A missing
+// file, e.g. the CFQ-only times, reads as no entries.
+func readBlkioV1(path string) []container.BlkioStatEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var entries []container.BlkioStatEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		entry := container.BlkioStatEntry{Op: fields[1]}
+		if _, err := fmt.Sscanf(fields[0], "%d:%d", &entry.Major, &entry.Minor); err != nil {
+			continue
+		}
+		if entry.Value, err = strconv.ParseUint(fields[2], 10, 64); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// readNetDev reads the interface counters of a /proc/<pid>/net/dev file, leaving out
+// the loopback interface like the Docker API
+func readNetDev(path string) (map[string]container.NetworkStats, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// After two header lines, each line is "<iface>: <8 receive> <8 transmit>" counters
+	networks := make(map[string]container.NetworkStats)
+	for _, line := range strings.Split(string(data), "\n") {
+		iface, counters, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		iface = strings.TrimSpace(iface)
+		fields := strings.Fields(counters)
+		if iface == "lo" || len(fields) < 16 {
+			continue
+		}
+		values := make([]uint64, 16)
+		for i := range values {
+			if values[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+				return nil, fmt.Errorf("%s: interface %s: %w", path, iface, err)
+			}
+		}
+		networks[iface] = container.NetworkStats{
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		}
+	}
+	return networks, nil
+}
+
+// readPressure reads a pressure file of "some|full avg10=... total=<usec>" lines
+func readPressure(path, resource string) []PressureStat {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var stats []PressureStat
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if value, ok := strings.CutPrefix(field, "total="); ok {
+				if total, err := strconv.ParseUint(value, 10, 64); err == nil {
+					stats = append(stats, PressureStat{Resource: resource, Kind: fields[0], Total: total})
+				}
+			}
+		}
+	}
+	return stats
+}
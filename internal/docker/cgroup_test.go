@@ -0,0 +1,275 @@
+package docker
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// writeFiles creates files with their contents under root, with their parent directories
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestReader creates a reader on a cgroup v2 or v1 fixture root and a /proc fixture
+// with 4 GiB of host memory
+func newTestReader(t *testing.T, v2 bool, files map[string]string) *CgroupReader {
+	t.Helper()
+	root, proc := t.TempDir(), t.TempDir()
+	if v2 {
+		files["cgroup.controllers"] = "cpu io memory pids\n"
+	} else {
+		files["memory/memory.usage_in_bytes"] = "0\n"
+	}
+	writeFiles(t, root, files)
+	writeFiles(t, proc, map[string]string{"meminfo": "MemTotal:        4194304 kB\n"})
+
+	r, err := NewCgroupReader(root, proc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.v2 != v2 {
+		t.Fatalf("v2 = %v, want %v", r.v2, v2)
+	}
+	return r
+}
+
+func TestCgroupReadV2(t *testing.T) {
+	dir := "system.slice/docker-" + testContainerID + ".scope"
+	r := newTestReader(t, true, map[string]string{
+		dir + "/cpu.stat":            "usage_usec 2000\nuser_usec 1500\nsystem_usec 500\nnr_periods 10\nnr_throttled 2\nthrottled_usec 30\n",
+		dir + "/memory.current":      "1048576\n",
+		dir + "/memory.stat":         "anon 524288\nfile 262144\n",
+		dir + "/memory.max":          "max\n",
+		dir + "/memory.swap.current": "4096\n",
+		dir + "/io.stat":             "8:0 rbytes=100 wbytes=200 rios=1 wios=2 dbytes=0 dios=0\n",
+		dir + "/pids.current":        "3\n",
+		dir + "/pids.max":            "100\n",
+		dir + "/memory.pressure":     "some avg10=0.00 avg60=0.00 avg300=0.00 total=1200\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=800\n",
+	})
+
+	stats := &container.StatsResponse{}
+	pressure, err := r.readV2(filepath.Join(r.root, dir), stats)
+	if err != nil {
+		t.Fatalf("readV2: %v", err)
+	}
+
+	cpu := stats.CPUStats
+	if cpu.CPUUsage.TotalUsage != 2000000 || cpu.CPUUsage.UsageInUsermode != 1500000 || cpu.CPUUsage.UsageInKernelmode != 500000 {
+		t.Errorf("CPU usage = %+v", cpu.CPUUsage)
+	}
+	if cpu.ThrottlingData != (container.ThrottlingData{Periods: 10, ThrottledPeriods: 2, ThrottledTime: 30000}) {
+		t.Errorf("throttling = %+v", cpu.ThrottlingData)
+	}
+	if stats.MemoryStats.Usage != 1048576 {
+		t.Errorf("memory usage = %d, want 1048576", stats.MemoryStats.Usage)
+	}
+	if stats.MemoryStats.Limit != 4<<30 {
+		t.Errorf("memory limit = %d, want the host memory %d", stats.MemoryStats.Limit, uint64(4<<30))
+	}
+	if got := stats.MemoryStats.Stats; got["anon"] != 524288 || got["file"] != 262144 || got["swap"] != 4096 {
+		t.Errorf("memory stats = %v", got)
+	}
+	wantIO := []container.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "read", Value: 100},
+		{Major: 8, Minor: 0, Op: "write", Value: 200},
+	}
+	if got := stats.BlkioStats.IoServiceBytesRecursive; len(got) != 2 || got[0] != wantIO[0] || got[1] != wantIO[1] {
+		t.Errorf("I/O bytes = %+v, want %+v", got, wantIO)
+	}
+	if got := stats.BlkioStats.IoServicedRecursive; len(got) != 2 || got[0].Value != 1 || got[1].Value != 2 {
+		t.Errorf("I/O operations = %+v", got)
+	}
+	if stats.PidsStats.Current != 3 || stats.PidsStats.Limit != 100 {
+		t.Errorf("pids = %+v", stats.PidsStats)
+	}
+	wantPressure := []PressureStat{
+		{Resource: "memory", Kind: "some", Total: 1200},
+		{Resource: "memory", Kind: "full", Total: 800},
+	}
+	if len(pressure) != 2 || pressure[0] != wantPressure[0] || pressure[1] != wantPressure[1] {
+		t.Errorf("pressure = %+v, want %+v", pressure, wantPressure)
+	}
+}
+
+func TestCgroupReadV1(t *testing.T) {
+	dir := "docker/" + testContainerID
+	r := newTestReader(t, false, map[string]string{
+		"cpuacct/" + dir + "/cpuacct.usage":                           "3000000\n",
+		"cpuacct/" + dir + "/cpuacct.stat":                            "user 20\nsystem 10\n",
+		"cpu/" + dir + "/cpu.stat":                                    "nr_periods 5\nnr_throttled 1\nthrottled_time 7000\n",
+		"memory/" + dir + "/memory.usage_in_bytes":                    "2097152\n",
+		"memory/" + dir + "/memory.stat":                              "rss 1048576\ncache 524288\n",
+		"memory/" + dir + "/memory.limit_in_bytes":                    "536870912\n",
+		"blkio/" + dir + "/blkio.throttle.io_service_bytes_recursive": "8:0 Read 100\n8:0 Write 200\nTotal 300\n",
+		"blkio/" + dir + "/blkio.throttle.io_serviced_recursive":      "8:0 Read 1\n8:0 Write 2\n",
+		"pids/" + dir + "/pids.current":                               "4\n",
+		"pids/" + dir + "/pids.max":                                   "max\n",
+	})
+
+	stats := &container.StatsResponse{}
+	if err := r.readV1(dir, stats); err != nil {
+		t.Fatalf("readV1: %v", err)
+	}
+
+	cpu := stats.CPUStats
+	if cpu.CPUUsage.TotalUsage != 3000000 || cpu.CPUUsage.UsageInUsermode != 200000000 || cpu.CPUUsage.UsageInKernelmode != 100000000 {
+		t.Errorf("CPU usage = %+v", cpu.CPUUsage)
+	}
+	if cpu.ThrottlingData != (container.ThrottlingData{Periods: 5, ThrottledPeriods: 1, ThrottledTime: 7000}) {
+		t.Errorf("throttling = %+v", cpu.ThrottlingData)
+	}
+	if stats.MemoryStats.Usage != 2097152 || stats.MemoryStats.Limit != 536870912 {
+		t.Errorf("memory usage and limit = %d, %d", stats.MemoryStats.Usage, stats.MemoryStats.Limit)
+	}
+	if got := stats.MemoryStats.Stats; got["rss"] != 1048576 || got["cache"] != 524288 {
+		t.Errorf("memory stats = %v", got)
+	}
+	want := []container.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 100},
+		{Major: 8, Minor: 0, Op: "Write", Value: 200},
+	}
+	if got := stats.BlkioStats.IoServiceBytesRecursive; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("I/O bytes = %+v, want %+v", got, want)
+	}
+	if stats.BlkioStats.IoServiceTimeRecursive != nil {
+		t.Errorf("I/O service time = %+v, want none without CFQ", stats.BlkioStats.IoServiceTimeRecursive)
+	}
+	if stats.PidsStats.Current != 4 || stats.PidsStats.Limit != math.MaxUint64 {
+		t.Errorf("pids = %+v", stats.PidsStats)
+	}
+}
+
+func TestCgroupContainerDir(t *testing.T) {
+	const pid = 1234
+	tests := []struct {
+		name    string
+		v2      bool
+		cgroup  string // /proc/<pid>/cgroup, none when empty
+		dirs    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "v2 custom parent",
+			v2:     true,
+			cgroup: "0::/custom.slice/ctr\n",
+			dirs:   []string{"custom.slice/ctr", "system.slice/docker-" + testContainerID + ".scope"},
+			want:   "custom.slice/ctr",
+		},
+		{
+			name: "v2 systemd fallback without a process cgroup",
+			v2:   true,
+			dirs: []string{"system.slice/docker-" + testContainerID + ".scope"},
+			want: "system.slice/docker-" + testContainerID + ".scope",
+		},
+		{
+			name:   "v2 outside the namespace",
+			v2:     true,
+			cgroup: "0::/../other\n",
+			dirs:   []string{"docker/" + testContainerID},
+			want:   "docker/" + testContainerID,
+		},
+		{
+			name:   "v2 process cgroup missing under the root",
+			v2:     true,
+			cgroup: "0::/elsewhere\n",
+			dirs:   []string{"docker/" + testContainerID},
+			want:   "docker/" + testContainerID,
+		},
+		{
+			name:   "v1 memory controller",
+			cgroup: "12:pids:/other\n4:cpu,cpuacct:/other\n3:memory:/custom/ctr\n0::/\n",
+			dirs:   []string{"memory/custom/ctr"},
+			want:   "custom/ctr",
+		},
+		{
+			name: "v1 cgroupfs fallback",
+			dirs: []string{"memory/docker/" + testContainerID},
+			want: "docker/" + testContainerID,
+		},
+		{
+			name:    "not found",
+			v2:      true,
+			cgroup:  "0::/\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReader(t, tt.v2, map[string]string{})
+			for _, dir := range tt.dirs {
+				if err := os.MkdirAll(filepath.Join(r.root, dir), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.cgroup != "" {
+				writeFiles(t, r.proc, map[string]string{"1234/cgroup": tt.cgroup})
+			}
+
+			got, err := r.containerDir(testContainerID, pid)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("containerDir = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("containerDir: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("containerDir = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCgroupNetworkStats(t *testing.T) {
+	dir := "system.slice/docker-" + testContainerID + ".scope"
+	r := newTestReader(t, true, map[string]string{
+		dir + "/cpu.stat":       "usage_usec 0\n",
+		dir + "/memory.current": "0\n",
+		dir + "/memory.stat":    "anon 0\n",
+	})
+	writeFiles(t, r.proc, map[string]string{
+		"1234/net/dev": `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:     500       5    0    0    0     0          0         0      500       5    0    0    0     0       0          0
+  eth0:    1000      10    1    2    0     0          0         0     2000      20    3    4    0     0       0          0
+`,
+	})
+
+	stats, _, err := r.Stats(testContainerID, 1234, true)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	want := map[string]container.NetworkStats{
+		"eth0": {RxBytes: 1000, RxPackets: 10, RxErrors: 1, RxDropped: 2, TxBytes: 2000, TxPackets: 20, TxErrors: 3, TxDropped: 4},
+	}
+	if len(stats.Networks) != len(want) || stats.Networks["eth0"] != want["eth0"] {
+		t.Errorf("networks = %+v, want %+v", stats.Networks, want)
+	}
+
+	// Host network containers see the interfaces of the host
+	stats, _, err = r.Stats(testContainerID, 1234, false)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if len(stats.Networks) != 0 {
+		t.Errorf("networks = %+v, want none without a network of its own", stats.Networks)
+	}
+}
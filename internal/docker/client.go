@@ -2,22 +2,36 @@ package docker
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/system"
 	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
 )
 
 // ContainerInfo holds container information
 type ContainerInfo struct {
-	ID           string
+	ID           string // short container ID
+	FullID       string
 	Name         string
 	Image        string
+	ImageID      string // short image ID
 	State        string
 	Health       string
 	Created      time.Time
@@ -27,6 +41,43 @@ type ContainerInfo struct {
 	ExitCode     int
 	OOMKilled    bool
 	Running      bool
+	Pid          int // host PID of the main process, 0 when not running or not inspected
+	Labels       map[string]string
+	NetworkMode  string // bridge, host, none, container:<id> or a network name
+
+	// Whether the container was inspected. Containers built from list summaries have
+	// no start and finish times, restart count, OOM flag or CPU limits.
+	Inspected bool
+
+	// CPU limits from the host config
+	NanoCPUs  int64
+	CPUQuota  int64
+	CPUPeriod int64
+	CPUShares int64
+
+	// Networks the container is attached to, sorted by name
+	Networks []ContainerNetwork
+}
+
+// ownNetwork reports whether the container has a network namespace of its own, so
+// that the interfaces seen by its processes carry only its traffic
+func (c ContainerInfo) ownNetwork() bool {
+	return c.NetworkMode != "host" && !strings.HasPrefix(c.NetworkMode, "container:")
+}
+
+// CPULimitCores returns the effective CPU limit in cores, or 0 when unlimited
+func (c ContainerInfo) CPULimitCores() float64 {
+	if c.NanoCPUs > 0 {
+		return float64(c.NanoCPUs) / 1e9
+	}
+	if c.CPUQuota > 0 {
+		period := c.CPUPeriod
+		if period <= 0 {
+			period = 100000 // kernel default CFS period in microseconds
+		}
+		return float64(c.CPUQuota) / float64(period)
+	}
+	return 0
 }
 
 // ContainerStats holds container resource statistics
@@ -38,29 +89,54 @@ type ContainerStats struct {
 	CPUPercent    float64
 	CPUUsageTotal uint64
 	CPUSystem     uint64
+	CPUUser       uint64
+	CPUKernel     uint64
+
+	// CPU throttling (CFS)
+	CPUPeriods          uint64
+	CPUThrottledPeriods uint64
+	CPUThrottledTime    uint64
 
 	// Memory
-	MemoryUsage   uint64
-	MemoryLimit   uint64
-	MemoryPercent float64
+	MemoryUsage      uint64
+	MemoryLimit      uint64
+	MemoryPercent    float64 // working set relative to the limit, as in docker stats
+	MemoryWorkingSet uint64  // usage minus inactive page cache
+	MemoryRSS        uint64
+	MemoryCache      uint64
+	MemorySwap       uint64
+	HasMemorySwap    bool // the daemon reported swap usage (not on cgroup v2 through the API)
+	MemoryPgFault    uint64
+	MemoryPgMajFault uint64
 
 	// Network (aggregated across all interfaces)
 	NetworkRxBytes uint64
 	NetworkTxBytes uint64
 	Networks       map[string]NetworkStats
 
-	// Block I/O
-	BlockRead  uint64
-	BlockWrite uint64
+	// Block I/O (aggregated across all devices)
+	BlockRead    uint64
+	BlockWrite   uint64
+	BlockDevices map[string]*BlockDeviceStats
 
 	// PIDs
 	PidsCount uint64
+	PidsLimit uint64
+
+	// Pressure stall information, only read from cgroup v2
+	Pressure []PressureStat
 }
 
 // NetworkStats holds per-interface network statistics
 type NetworkStats struct {
-	RxBytes uint64
-	TxBytes uint64
+	RxBytes   uint64
+	TxBytes   uint64
+	RxPackets uint64
+	TxPackets uint64
+	RxErrors  uint64
+	TxErrors  uint64
+	RxDropped uint64
+	TxDropped uint64
 }
 
 // EngineInfo holds Docker engine information
@@ -77,30 +153,161 @@ type EngineInfo struct {
 	MemTotal          int64
 }
 
+// ImageInfo holds image information
+type ImageInfo struct {
+	ID      string
+	Tags    []string // repository:tag references, empty for dangling images
+	Digests []string // repository@digest references
+	Created time.Time
+	Size    int64
+}
+
+// Dangling reports whether the image has no tag
+func (i ImageInfo) Dangling() bool {
+	return len(i.Tags) == 0
+}
+
+// TLSOptions holds the TLS settings for the Docker daemon connection
+type TLSOptions struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	Verify   bool
+}
+
+// Enabled reports whether TLS should be used for the connection
+func (o TLSOptions) Enabled() bool {
+	return o.Verify || o.CAFile != "" || o.CertFile != "" || o.KeyFile != ""
+}
+
+// Options holds the settings used to create a Client
+type Options struct {
+	Host   string
+	TLS    TLSOptions
+	Filter *Filter
+
+	// Concurrent inspect calls of ListContainers (values below 1 mean 1)
+	InspectConcurrency int
+	// Build the container list from the list summaries without inspecting each container
+	SkipInspect bool
+
+	// Read container stats from this cgroup filesystem instead of the Docker API.
+	// Only valid when the daemon runs on the same host, whose /proc is at ProcRoot.
+	CgroupRoot string
+	ProcRoot   string
+}
+
 // Client wraps the Docker client
 type Client struct {
-	cli *client.Client
+	cli    *client.Client
+	filter *Filter
+
+	inspectConcurrency int
+	skipInspect        bool
+
+	local  bool          // the daemon is reached over a unix socket, so it runs on this host
+	cgroup *CgroupReader // nil reads stats from the Docker API
+}
+
+// InspectError is a failed inspect call of a listed container
+type InspectError struct {
+	ID   string // short container ID
+	Name string
+	Err  error
+}
+
+// Error implements error
+func (e *InspectError) Error() string {
+	return fmt.Sprintf("inspect container %s: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *InspectError) Unwrap() error {
+	return e.Err
 }
 
 // NewClient creates a new Docker client
-func NewClient(host string) (*Client, error) {
+func NewClient(options Options) (*Client, error) {
 	opts := []client.Opt{
 		client.WithAPIVersionNegotiation(),
 	}
 
 	// Set host if provided
-	if host != "" {
-		opts = append(opts, client.WithHost(host))
+	if options.Host != "" {
+		opts = append(opts, client.WithHost(options.Host))
 	} else {
 		opts = append(opts, client.FromEnv)
 	}
 
+	// TLS only applies to TCP hosts, as in the Docker CLI. It is applied after the host
+	// so it overrides any TLS settings from the environment.
+	if options.TLS.Enabled() && strings.HasPrefix(options.Host, "tcp://") {
+		tlsConfig, err := newTLSConfig(options.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, withTLSConfig(tlsConfig))
+	}
+
+	var cgroup *CgroupReader
+	if options.CgroupRoot != "" {
+		var err error
+		cgroup, err = NewCgroupReader(options.CgroupRoot, options.ProcRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{cli: cli}, nil
+	return &Client{
+		cli:                cli,
+		filter:             options.Filter,
+		inspectConcurrency: max(options.InspectConcurrency, 1),
+		skipInspect:        options.SkipInspect,
+		local:              strings.HasPrefix(cli.DaemonHost(), "unix://"),
+		cgroup:             cgroup,
+	}, nil
+}
+
+// newTLSConfig loads the CA and client certificates and builds a TLS config
+func newTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.New("docker TLS: both client certificate and key must be set")
+	}
+
+	tlsConfig, err := tlsconfig.Client(tlsconfig.Options{
+		CAFile:             opts.CAFile,
+		CertFile:           opts.CertFile,
+		KeyFile:            opts.KeyFile,
+		InsecureSkipVerify: !opts.Verify,
+		ExclusiveRootPools: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("docker TLS: %w", err)
+	}
+
+	return tlsConfig, nil
+}
+
+// withTLSConfig applies a TLS config to the client transport
+func withTLSConfig(tlsConfig *tls.Config) client.Opt {
+	return func(c *client.Client) error {
+		transport, ok := c.HTTPClient().Transport.(*http.Transport)
+		if !ok {
+			return fmt.Errorf("docker TLS: cannot apply TLS config to transport %T", c.HTTPClient().Transport)
+		}
+		transport.TLSClientConfig = tlsConfig
+		return nil
+	}
+}
+
+// Filter returns the container filter of the client, or nil when all containers are collected
+func (c *Client) Filter() *Filter {
+	return c.filter
 }
 
 // Close closes the Docker client
@@ -108,29 +315,179 @@ func (c *Client) Close() error {
 	return c.cli.Close()
 }
 
+// Local reports whether the daemon is reached over a unix socket and therefore runs
+// on the same host as the exporter
+func (c *Client) Local() bool {
+	return c.local
+}
+
 // Ping checks if the Docker daemon is accessible
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.cli.Ping(ctx)
 	return err
 }
 
-// ListContainers returns a list of all containers
-func (c *Client) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
-	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+// ListContainers returns a list of all containers that pass the client filter. Each
+// container is inspected, with a bounded number of concurrent calls, unless the client
+// skips inspects. Containers whose inspect fails are left out and returned as
+// InspectErrors; containers removed since the list are dropped silently. When ctx
+// ends before every container is inspected, the whole list fails with its error; a
+// ctx that only ends after the last inspect does not fail it.
+func (c *Client) ListContainers(ctx context.Context) ([]ContainerInfo, []*InspectError, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: c.filter.Args(),
+	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var result []ContainerInfo
+	var matched []container.Summary
 	for _, cont := range containers {
-		info, err := c.InspectContainer(ctx, cont.ID)
-		if err != nil {
+		if c.filter.Match(cont) {
+			matched = append(matched, cont)
+		}
+	}
+
+	if c.skipInspect {
+		result := make([]ContainerInfo, 0, len(matched))
+		for _, cont := range matched {
+			result = append(result, containerInfoFromSummary(cont))
+		}
+		return result, nil, nil
+	}
+
+	var (
+		wg    sync.WaitGroup
+		infos = make([]ContainerInfo, len(matched))
+		errs  = make([]error, len(matched))
+		jobs  = make(chan int)
+	)
+	for range min(c.inspectConcurrency, len(matched)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				infos[i], errs[i] = c.InspectContainer(ctx, matched[i].ID)
+			}
+		}()
+	}
+	for i := range matched {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var (
+		result      []ContainerInfo
+		inspectErrs []*InspectError
+	)
+	for i, cont := range matched {
+		switch {
+		case errs[i] == nil:
+			result = append(result, infos[i])
+		case errors.Is(errs[i], context.DeadlineExceeded), errors.Is(errs[i], context.Canceled):
+			// A deadline hit halfway fails the whole list rather than leaving out
+			// every container that was not inspected in time
+			return nil, nil, errs[i]
+		case cerrdefs.IsNotFound(errs[i]):
+			// Removed between the list and the inspect call
+		default:
+			info := containerInfoFromSummary(cont)
+			inspectErrs = append(inspectErrs, &InspectError{ID: info.ID, Name: info.Name, Err: errs[i]})
+		}
+	}
+
+	return result, inspectErrs, nil
+}
+
+// containerInfoFromSummary builds a container from its list summary. The summary has
+// no start and finish times, restart count, OOM flag or CPU limits; health and exit
+// code are parsed from the status text.
+func containerInfoFromSummary(cont container.Summary) ContainerInfo {
+	info := ContainerInfo{
+		ID:       cont.ID[:12],
+		FullID:   cont.ID,
+		Image:    cont.Image,
+		ImageID:  shortImageID(cont.ImageID),
+		State:    cont.State,
+		Health:   summaryHealth(cont.Status),
+		ExitCode: summaryExitCode(cont.Status),
+		Running:  cont.State == container.StateRunning,
+		Labels:   cont.Labels,
+	}
+	if len(cont.Names) > 0 {
+		info.Name = strings.TrimPrefix(cont.Names[0], "/")
+	}
+	if cont.Created > 0 {
+		info.Created = time.Unix(cont.Created, 0)
+	}
+	info.NetworkMode = cont.HostConfig.NetworkMode
+	if cont.NetworkSettings != nil {
+		info.Networks = containerNetworks(cont.NetworkSettings.Networks)
+	}
+	return info
+}
+
+// summaryHealth returns the health status from a summary status text such as
+// "Up 5 minutes (healthy)"
+func summaryHealth(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(healthy)"):
+		return "healthy"
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.HasSuffix(status, "(health: starting)"):
+		return "starting"
+	}
+	return "none"
+}
+
+// summaryExitCode returns the exit code from a summary status text such as
+// "Exited (137) 5 minutes ago", or 0 when the status has none
+func summaryExitCode(status string) int {
+	for _, prefix := range []string{"Exited (", "Restarting ("} {
+		if rest, ok := strings.CutPrefix(status, prefix); ok {
+			code, _, _ := strings.Cut(rest, ")")
+			n, _ := strconv.Atoi(code)
+			return n
+		}
+	}
+	return 0
+}
+
+// containerNetworks returns the network attachments of a container sorted by name
+func containerNetworks(endpoints map[string]*network.EndpointSettings) []ContainerNetwork {
+	var networks []ContainerNetwork
+	for name, endpoint := range endpoints {
+		if endpoint == nil {
 			continue
 		}
-		result = append(result, info)
+		networks = append(networks, ContainerNetwork{Name: name, IPAddress: endpoint.IPAddress})
 	}
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].Name < networks[j].Name
+	})
+	return networks
+}
 
-	return result, nil
+// container returns a single container, inspected unless the client skips inspects
+func (c *Client) container(ctx context.Context, containerID string) (ContainerInfo, error) {
+	if !c.skipInspect {
+		return c.InspectContainer(ctx, containerID)
+	}
+
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("id", containerID)),
+	})
+	if err != nil {
+		return ContainerInfo{}, err
+	}
+	if len(containers) == 0 {
+		return ContainerInfo{}, fmt.Errorf("container %s: %w", containerID, cerrdefs.ErrNotFound)
+	}
+	return containerInfoFromSummary(containers[0]), nil
 }
 
 // InspectContainer returns detailed information about a container
@@ -142,6 +499,8 @@ func (c *Client) InspectContainer(ctx context.Context, containerID string) (Cont
 
 	info := ContainerInfo{
 		ID:           inspect.ID[:12],
+		FullID:       inspect.ID,
+		ImageID:      shortImageID(inspect.Image),
 		Name:         strings.TrimPrefix(inspect.Name, "/"),
 		Image:        inspect.Config.Image,
 		State:        inspect.State.Status,
@@ -149,6 +508,9 @@ func (c *Client) InspectContainer(ctx context.Context, containerID string) (Cont
 		ExitCode:     inspect.State.ExitCode,
 		OOMKilled:    inspect.State.OOMKilled,
 		Running:      inspect.State.Running,
+		Pid:          inspect.State.Pid,
+		Labels:       inspect.Config.Labels,
+		Inspected:    true,
 	}
 
 	// Parse timestamps
@@ -162,6 +524,20 @@ func (c *Client) InspectContainer(ctx context.Context, containerID string) (Cont
 		info.Finished = finished
 	}
 
+	// CPU limits
+	if inspect.HostConfig != nil {
+		info.NetworkMode = string(inspect.HostConfig.NetworkMode)
+		info.NanoCPUs = inspect.HostConfig.NanoCPUs
+		info.CPUQuota = inspect.HostConfig.CPUQuota
+		info.CPUPeriod = inspect.HostConfig.CPUPeriod
+		info.CPUShares = inspect.HostConfig.CPUShares
+	}
+
+	// Network attachments
+	if inspect.NetworkSettings != nil {
+		info.Networks = containerNetworks(inspect.NetworkSettings.Networks)
+	}
+
 	// Health status
 	if inspect.State.Health != nil {
 		info.Health = inspect.State.Health.Status
@@ -172,8 +548,22 @@ func (c *Client) InspectContainer(ctx context.Context, containerID string) (Cont
 	return info, nil
 }
 
-// GetContainerStats returns resource statistics for a container
-func (c *Client) GetContainerStats(ctx context.Context, containerID string, name string) (*ContainerStats, error) {
+// GetContainerStats returns resource statistics for a container, from the cgroup
+// filesystem when the client has a cgroup root and from the Docker API otherwise.
+// Reading cgroups needs the full container ID, and the host PID of its main process
+// to find cgroups outside the default locations and to read its network counters.
+func (c *Client) GetContainerStats(ctx context.Context, cont ContainerInfo) (*ContainerStats, error) {
+	containerID, name := cont.FullID, cont.Name
+	if c.cgroup != nil {
+		stats, pressure, err := c.cgroup.Stats(containerID, cont.Pid, cont.ownNetwork())
+		if err != nil {
+			return nil, err
+		}
+		result := containerStats(containerID, name, stats, true)
+		result.Pressure = pressure
+		return result, nil
+	}
+
 	resp, err := c.cli.ContainerStats(ctx, containerID, false)
 	if err != nil {
 		return nil, err
@@ -190,6 +580,12 @@ func (c *Client) GetContainerStats(ctx context.Context, containerID string, name
 		return nil, err
 	}
 
+	return containerStats(containerID, name, &stats, c.local), nil
+}
+
+// containerStats converts a stats response of the Docker API format. local resolves
+// block device names from this host.
+func containerStats(containerID string, name string, stats *container.StatsResponse, local bool) *ContainerStats {
 	result := &ContainerStats{
 		ID:       containerID[:12],
 		Name:     name,
@@ -197,41 +593,54 @@ func (c *Client) GetContainerStats(ctx context.Context, containerID string, name
 	}
 
 	// Calculate CPU percentage
-	result.CPUPercent = calculateCPUPercent(&stats)
+	result.CPUPercent = calculateCPUPercent(stats)
 	result.CPUUsageTotal = stats.CPUStats.CPUUsage.TotalUsage
 	result.CPUSystem = stats.CPUStats.SystemUsage
+	result.CPUUser = stats.CPUStats.CPUUsage.UsageInUsermode
+	result.CPUKernel = stats.CPUStats.CPUUsage.UsageInKernelmode
+	result.CPUPeriods = stats.CPUStats.ThrottlingData.Periods
+	result.CPUThrottledPeriods = stats.CPUStats.ThrottlingData.ThrottledPeriods
+	result.CPUThrottledTime = stats.CPUStats.ThrottlingData.ThrottledTime
 
 	// Memory stats
 	result.MemoryUsage = stats.MemoryStats.Usage
 	result.MemoryLimit = stats.MemoryStats.Limit
+	setMemoryBreakdown(result, stats.MemoryStats.Stats)
 	if result.MemoryLimit > 0 {
-		result.MemoryPercent = float64(result.MemoryUsage) / float64(result.MemoryLimit) * 100.0
+		result.MemoryPercent = float64(result.MemoryWorkingSet) / float64(result.MemoryLimit) * 100.0
 	}
 
 	// Network stats
 	for iface, netStats := range stats.Networks {
 		result.Networks[iface] = NetworkStats{
-			RxBytes: netStats.RxBytes,
-			TxBytes: netStats.TxBytes,
+			RxBytes:   netStats.RxBytes,
+			TxBytes:   netStats.TxBytes,
+			RxPackets: netStats.RxPackets,
+			TxPackets: netStats.TxPackets,
+			RxErrors:  netStats.RxErrors,
+			TxErrors:  netStats.TxErrors,
+			RxDropped: netStats.RxDropped,
+			TxDropped: netStats.TxDropped,
 		}
 		result.NetworkRxBytes += netStats.RxBytes
 		result.NetworkTxBytes += netStats.TxBytes
 	}
 
 	// Block I/O stats
-	for _, bioEntry := range stats.BlkioStats.IoServiceBytesRecursive {
-		switch bioEntry.Op {
-		case "read", "Read":
-			result.BlockRead += bioEntry.Value
-		case "write", "Write":
-			result.BlockWrite += bioEntry.Value
-		}
+	result.BlockDevices = blockDeviceStats(stats.BlkioStats, local)
+	for _, dev := range result.BlockDevices {
+		result.BlockRead += dev.ReadBytes
+		result.BlockWrite += dev.WriteBytes
 	}
 
 	// PIDs
 	result.PidsCount = stats.PidsStats.Current
+	if stats.PidsStats.Limit != math.MaxUint64 {
+		// cgroup v2 reports an unlimited pids.max as the maximum value
+		result.PidsLimit = stats.PidsStats.Limit
+	}
 
-	return result, nil
+	return result
 }
 
 // GetEngineInfo returns Docker engine information
@@ -255,9 +664,44 @@ func (c *Client) GetEngineInfo(ctx context.Context) (*EngineInfo, error) {
 	}, nil
 }
 
-// GetImages returns the list of images
-func (c *Client) GetImages(ctx context.Context) ([]image.Summary, error) {
-	return c.cli.ImageList(ctx, image.ListOptions{})
+// GetImages returns the list of images with their shared layer sizes
+func (c *Client) GetImages(ctx context.Context) ([]ImageInfo, error) {
+	images, err := c.cli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ImageInfo, 0, len(images))
+	for _, img := range images {
+		info := ImageInfo{
+			ID:      shortImageID(img.ID),
+			Tags:    img.RepoTags,
+			Digests: img.RepoDigests,
+			Created: time.Unix(img.Created, 0),
+			Size:    img.Size,
+		}
+		if len(info.Tags) == 1 && info.Tags[0] == "<none>:<none>" {
+			info.Tags = nil
+		}
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// ImageUsage returns the number of containers, stopped ones included, using each image
+// by short image ID. Unlike ListContainers it covers every container, whatever the filter.
+func (c *Client) ImageUsage(ctx context.Context) (map[string]int, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]int)
+	for _, cont := range containers {
+		usage[shortImageID(cont.ImageID)]++
+	}
+	return usage, nil
 }
 
 // Info returns system information
@@ -265,6 +709,54 @@ func (c *Client) Info(ctx context.Context) (system.Info, error) {
 	return c.cli.Info(ctx)
 }
 
+// setMemoryBreakdown fills the memory breakdown from the raw memory.stat values.
+// cgroup v1 exposes hierarchical total_* keys next to the plain ones, while cgroup v2
+// uses anon/file instead of rss/cache.
+func setMemoryBreakdown(result *ContainerStats, raw map[string]uint64) {
+	// Working set, calculated the same way as the Docker CLI
+	result.MemoryWorkingSet = result.MemoryUsage
+	if inactive, ok := memoryStat(raw, "total_inactive_file", "inactive_file"); ok && inactive < result.MemoryUsage {
+		result.MemoryWorkingSet = result.MemoryUsage - inactive
+	}
+
+	result.MemoryRSS, _ = memoryStat(raw, "total_rss", "rss", "anon")
+	result.MemoryCache, _ = memoryStat(raw, "total_cache", "cache", "file")
+	result.MemorySwap, result.HasMemorySwap = memoryStat(raw, "total_swap", "swap")
+	result.MemoryPgFault, _ = memoryStat(raw, "total_pgfault", "pgfault")
+	result.MemoryPgMajFault, _ = memoryStat(raw, "total_pgmajfault", "pgmajfault")
+}
+
+// memoryStat returns the first of the given memory.stat keys that is present
+func memoryStat(raw map[string]uint64, keys ...string) (uint64, bool) {
+	for _, key := range keys {
+		if v, ok := raw[key]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// shortImageID strips the digest algorithm and truncates an image ID to 12 characters
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// SplitImageReference splits an image reference into repository and tag or digest
+func SplitImageReference(ref string) (repository, tag string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	// A colon after the last slash separates the tag; an earlier one is a registry port
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
 // calculateCPUPercent calculates the CPU usage percentage
 func calculateCPUPercent(stats *container.StatsResponse) float64 {
 	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage - stats.PreCPUStats.CPUUsage.TotalUsage)
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestNewClientUnixIgnoresTLS checks that TLS settings, e.g. from DOCKER_TLS_VERIFY,
+// do not turn a unix socket connection into HTTPS
+func TestNewClientUnixIgnoresTLS(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, so stay clear of long temp dirs
+	dir, err := os.MkdirTemp("", "docker-exporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "1.47")
+		w.Write([]byte("OK"))
+	})}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	client, err := NewClient(Options{
+		Host: "unix://" + socket,
+		TLS:  TLSOptions{Verify: true},
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// Disk usage object types
+const (
+	DiskUsageImages     = "images"
+	DiskUsageContainers = "containers"
+	DiskUsageVolumes    = "volumes"
+	DiskUsageBuildCache = "build_cache"
+)
+
+// ObjectUsage holds the disk space used by one type of object
+type ObjectUsage struct {
+	Type        string
+	Count       int
+	Active      int   // objects in use by a container
+	Size        int64 // bytes used on disk
+	Reclaimable int64 // bytes freed by pruning unused objects
+}
+
+// ContainerUsage holds the disk usage of a container
+type ContainerUsage struct {
+	ID         string
+	Name       string
+	SizeRw     int64 // writable layer
+	SizeRootFs int64 // all layers including the image
+}
+
+// DiskUsage holds the result of a /system/df call
+type DiskUsage struct {
+	Time       time.Time
+	Objects    []ObjectUsage
+	Containers []ContainerUsage
+	Volumes    []VolumeUsage
+}
+
+// GetDiskUsage returns the disk usage reported by /system/df. This call is expensive
+// on hosts with many volumes because the daemon walks every volume directory.
+// Per-container sizes only include containers that pass the filter.
+func (c *Client) GetDiskUsage(ctx context.Context) (*DiskUsage, error) {
+	df, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiskUsage{Time: time.Now()}
+
+	// Images share layers, so the total is the layer size rather than the sum of image
+	// sizes. As in `docker system df`, what is not held by the unique layers of images
+	// in use is reclaimable.
+	images := ObjectUsage{Type: DiskUsageImages, Count: len(df.Images), Size: df.LayersSize}
+	var used int64
+	for _, img := range df.Images {
+		if img.Containers == 0 {
+			continue
+		}
+		images.Active++
+		if img.Size >= 0 && img.SharedSize >= 0 {
+			used += img.Size - img.SharedSize
+		}
+	}
+	images.Reclaimable = max(df.LayersSize-used, 0)
+
+	containers := ObjectUsage{Type: DiskUsageContainers, Count: len(df.Containers)}
+	for _, cont := range df.Containers {
+		containers.Size += cont.SizeRw
+		if cont.State == container.StateRunning {
+			containers.Active++
+		} else {
+			containers.Reclaimable += cont.SizeRw
+		}
+
+		if !c.filter.Match(*cont) {
+			continue
+		}
+		name := ""
+		if len(cont.Names) > 0 {
+			name = strings.TrimPrefix(cont.Names[0], "/")
+		}
+		result.Containers = append(result.Containers, ContainerUsage{
+			ID:         cont.ID[:12],
+			Name:       name,
+			SizeRw:     cont.SizeRw,
+			SizeRootFs: cont.SizeRootFs,
+		})
+	}
+
+	volumes := ObjectUsage{Type: DiskUsageVolumes, Count: len(df.Volumes)}
+	for _, vol := range df.Volumes {
+		usage := VolumeUsage{Name: vol.Name, Size: -1, RefCount: -1}
+		if vol.UsageData != nil {
+			usage.Size = vol.UsageData.Size
+			usage.RefCount = vol.UsageData.RefCount
+		}
+		result.Volumes = append(result.Volumes, usage)
+
+		if usage.RefCount > 0 {
+			volumes.Active++
+		}
+		if usage.Size > 0 {
+			volumes.Size += usage.Size
+			if usage.RefCount == 0 {
+				volumes.Reclaimable += usage.Size
+			}
+		}
+	}
+
+	// Shared cache records are already counted by the records sharing them
+	buildCache := ObjectUsage{Type: DiskUsageBuildCache, Count: len(df.BuildCache)}
+	for _, rec := range df.BuildCache {
+		if rec.InUse {
+			buildCache.Active++
+		}
+		if rec.Shared {
+			continue
+		}
+		buildCache.Size += rec.Size
+		if !rec.InUse {
+			buildCache.Reclaimable += rec.Size
+		}
+	}
+
+	result.Objects = []ObjectUsage{images, containers, volumes, buildCache}
+	return result, nil
+}
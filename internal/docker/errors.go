@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"context"
+	"errors"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/client"
+)
+
+// Error reasons reported by ErrorReason
+const (
+	ReasonTimeout    = "timeout"
+	ReasonCanceled   = "canceled"
+	ReasonConnection = "connection"
+	ReasonNotFound   = "not_found"
+	ReasonAPI        = "api"
+)
+
+// ErrorReason classifies a Docker API error into a short reason for metric labels
+func ErrorReason(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ReasonTimeout
+	case errors.Is(err, context.Canceled):
+		return ReasonCanceled
+	case client.IsErrConnectionFailed(err):
+		return ReasonConnection
+	case cerrdefs.IsNotFound(err):
+		return ReasonNotFound
+	}
+	return ReasonAPI
+}
@@ -0,0 +1,238 @@
+package docker
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// Selector kinds
+const (
+	SelectorName  = "name"
+	SelectorImage = "image"
+	SelectorLabel = "label"
+	SelectorState = "state"
+)
+
+// validStates are the container states accepted by Docker's status filter
+var validStates = map[string]bool{
+	"created":    true,
+	"restarting": true,
+	"running":    true,
+	"removing":   true,
+	"paused":     true,
+	"exited":     true,
+	"dead":       true,
+}
+
+// Selector matches containers on a single attribute. Supported forms are
+// name=<regex>, image=<glob>, label=<key> or label=<key>=<value>, and state=<state>.
+type Selector struct {
+	Kind  string
+	Key   string
+	Value string
+
+	name *regexp.Regexp
+}
+
+// ParseSelector parses a selector expression
+func ParseSelector(expr string) (Selector, error) {
+	kind, value, ok := strings.Cut(expr, "=")
+	if !ok || value == "" {
+		return Selector{}, fmt.Errorf("invalid selector %q: expected <kind>=<value>", expr)
+	}
+
+	sel := Selector{Kind: kind, Value: value}
+	switch kind {
+	case SelectorName:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return Selector{}, fmt.Errorf("invalid selector %q: %w", expr, err)
+		}
+		sel.name = re
+	case SelectorImage:
+		if _, err := path.Match(value, ""); err != nil {
+			return Selector{}, fmt.Errorf("invalid selector %q: %w", expr, err)
+		}
+	case SelectorLabel:
+		sel.Key, sel.Value, _ = strings.Cut(value, "=")
+	case SelectorState:
+		sel.Value = strings.ToLower(value)
+		if !validStates[sel.Value] {
+			return Selector{}, fmt.Errorf("invalid selector %q: unknown state %q", expr, value)
+		}
+	default:
+		return Selector{}, fmt.Errorf("invalid selector %q: unknown kind %q (want name, image, label or state)", expr, kind)
+	}
+
+	return sel, nil
+}
+
+// filterTarget holds the container attributes selectors match on
+type filterTarget struct {
+	Names  []string
+	Image  string
+	Labels map[string]string
+	State  string
+}
+
+// summaryTarget builds a filterTarget from a container list entry
+func summaryTarget(cont container.Summary) filterTarget {
+	names := make([]string, len(cont.Names))
+	for i, name := range cont.Names {
+		names[i] = strings.TrimPrefix(name, "/")
+	}
+	return filterTarget{Names: names, Image: cont.Image, Labels: cont.Labels, State: string(cont.State)}
+}
+
+// infoTarget builds a filterTarget from inspected container information
+func infoTarget(info ContainerInfo) filterTarget {
+	return filterTarget{Names: []string{info.Name}, Image: info.Image, Labels: info.Labels, State: info.State}
+}
+
+// match reports whether the selector matches a container
+func (s Selector) match(t filterTarget) bool {
+	switch s.Kind {
+	case SelectorName:
+		for _, name := range t.Names {
+			if s.name.MatchString(name) {
+				return true
+			}
+		}
+		return false
+	case SelectorImage:
+		ok, _ := path.Match(s.Value, t.Image)
+		return ok
+	case SelectorLabel:
+		value, exists := t.Labels[s.Key]
+		if !exists {
+			return false
+		}
+		return s.Value == "" || value == s.Value
+	case SelectorState:
+		return t.State == s.Value
+	}
+	return false
+}
+
+// Filter selects which containers are collected.
+//
+// A container is collected when it matches every kind of include selector and no
+// exclude selector. Within a kind, name, image and state selectors are ORed while
+// label selectors must all match, which mirrors Docker's own filter semantics.
+type Filter struct {
+	Include []Selector
+	Exclude []Selector
+}
+
+// NewFilter parses include and exclude selector expressions
+func NewFilter(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+	for _, expr := range include {
+		sel, err := ParseSelector(expr)
+		if err != nil {
+			return nil, fmt.Errorf("include: %w", err)
+		}
+		f.Include = append(f.Include, sel)
+	}
+	for _, expr := range exclude {
+		sel, err := ParseSelector(expr)
+		if err != nil {
+			return nil, fmt.Errorf("exclude: %w", err)
+		}
+		f.Exclude = append(f.Exclude, sel)
+	}
+	return f, nil
+}
+
+// Args returns the include selectors Docker can evaluate server-side.
+// Name and image selectors are matched client-side because Docker's semantics differ.
+func (f *Filter) Args() filters.Args {
+	args := filters.NewArgs()
+	if f == nil {
+		return args
+	}
+	for _, sel := range f.Include {
+		switch sel.Kind {
+		case SelectorLabel:
+			if sel.Value == "" {
+				args.Add("label", sel.Key)
+			} else {
+				args.Add("label", sel.Key+"="+sel.Value)
+			}
+		case SelectorState:
+			args.Add("status", sel.Value)
+		}
+	}
+	return args
+}
+
+// Match reports whether a container list entry passes the filter
+func (f *Filter) Match(cont container.Summary) bool {
+	return f.match(summaryTarget(cont))
+}
+
+// MatchInfo reports whether inspected container information passes the filter
+func (f *Filter) MatchInfo(info ContainerInfo) bool {
+	return f.match(infoTarget(info))
+}
+
+// MatchEvent reports whether the container of an event passes the filter.
+// Events do not carry the container state, so state selectors are ignored.
+func (f *Filter) MatchEvent(msg events.Message) bool {
+	if f == nil {
+		return true
+	}
+	stateless := &Filter{}
+	for _, sel := range f.Include {
+		if sel.Kind != SelectorState {
+			stateless.Include = append(stateless.Include, sel)
+		}
+	}
+	for _, sel := range f.Exclude {
+		if sel.Kind != SelectorState {
+			stateless.Exclude = append(stateless.Exclude, sel)
+		}
+	}
+
+	// Event attributes hold the container labels next to name and image
+	attrs := msg.Actor.Attributes
+	return stateless.match(filterTarget{Names: []string{attrs["name"]}, Image: attrs["image"], Labels: attrs})
+}
+
+// match applies the include and exclude selectors to a container
+func (f *Filter) match(t filterTarget) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, sel := range f.Exclude {
+		if sel.match(t) {
+			return false
+		}
+	}
+
+	matched := make(map[string]bool)
+	for _, sel := range f.Include {
+		ok := sel.match(t)
+		if sel.Kind == SelectorLabel {
+			if !ok {
+				return false
+			}
+			continue
+		}
+		matched[sel.Kind] = matched[sel.Kind] || ok
+	}
+	for _, ok := range matched {
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
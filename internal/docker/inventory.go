@@ -0,0 +1,175 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// Events subscribes to the container and network events stream of the Docker daemon,
+// replaying the events from since on when it is not zero. The error channel receives
+// a value when the stream ends.
+func (c *Client) Events(ctx context.Context, since time.Time) (<-chan events.Message, <-chan error) {
+	options := events.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(events.ContainerEventType)),
+			filters.Arg("type", string(events.NetworkEventType)),
+		),
+	}
+	if !since.IsZero() {
+		options.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+	}
+	return c.cli.Events(ctx, options)
+}
+
+// Inventory keeps an in-memory view of the containers that is updated from
+// the Docker events stream instead of being rebuilt on every collection
+type Inventory struct {
+	client *Client
+
+	mu         sync.RWMutex
+	containers map[string]ContainerInfo
+	synced     bool
+}
+
+// NewInventory creates an empty inventory. Call Resync to fill it.
+func NewInventory(client *Client) *Inventory {
+	return &Inventory{
+		client:     client,
+		containers: make(map[string]ContainerInfo),
+	}
+}
+
+// Resync replaces the inventory with a full container list from the daemon. It
+// returns the inspect errors of the containers left out, see Client.ListContainers.
+func (inv *Inventory) Resync(ctx context.Context) ([]*InspectError, error) {
+	containers, inspectErrs, err := inv.client.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := make(map[string]ContainerInfo, len(containers))
+	for _, cont := range containers {
+		fresh[cont.ID] = cont
+	}
+
+	inv.mu.Lock()
+	inv.containers = fresh
+	inv.synced = true
+	inv.mu.Unlock()
+
+	return inspectErrs, nil
+}
+
+// Invalidate marks the inventory as stale until the next Resync
+func (inv *Inventory) Invalidate() {
+	inv.mu.Lock()
+	inv.synced = false
+	inv.mu.Unlock()
+}
+
+// Synced reports whether the inventory reflects the daemon state
+func (inv *Inventory) Synced() bool {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	return inv.synced
+}
+
+// List returns the containers in the inventory sorted by name
+func (inv *Inventory) List() []ContainerInfo {
+	inv.mu.RLock()
+	result := make([]ContainerInfo, 0, len(inv.containers))
+	for _, cont := range inv.containers {
+		result = append(result, cont)
+	}
+	inv.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Handle applies a container event to the inventory. Lifecycle events, and network
+// connect and disconnect events of the container, re-read the container, inspected or
+// from its list summary like ListContainers; destroy removes it. Other events are
+// ignored.
+func (inv *Inventory) Handle(ctx context.Context, msg events.Message) error {
+	fullID, name := msg.Actor.ID, msg.Actor.Attributes["name"]
+	switch msg.Type {
+	case events.ContainerEventType:
+	case events.NetworkEventType:
+		if msg.Action != events.ActionConnect && msg.Action != events.ActionDisconnect {
+			return nil
+		}
+		// The actor is the network, the container is an attribute
+		fullID, name = msg.Actor.Attributes["container"], ""
+	default:
+		return nil
+	}
+	if len(fullID) < 12 {
+		return nil
+	}
+	id := fullID[:12]
+
+	switch {
+	case msg.Type == events.NetworkEventType:
+	case msg.Action == events.ActionDestroy:
+		inv.remove(id)
+		return nil
+	case msg.Action == events.ActionCreate,
+		msg.Action == events.ActionStart,
+		msg.Action == events.ActionRestart,
+		msg.Action == events.ActionStop,
+		msg.Action == events.ActionDie,
+		msg.Action == events.ActionKill,
+		msg.Action == events.ActionOOM,
+		msg.Action == events.ActionPause,
+		msg.Action == events.ActionUnPause,
+		msg.Action == events.ActionRename,
+		msg.Action == events.ActionUpdate,
+		strings.HasPrefix(string(msg.Action), string(events.ActionHealthStatus)):
+	default:
+		return nil
+	}
+
+	info, err := inv.client.container(ctx, fullID)
+	if err != nil {
+		// The container may already be gone when events arrive late
+		if cerrdefs.IsNotFound(err) {
+			inv.remove(id)
+			return nil
+		}
+		if name == "" {
+			inv.mu.RLock()
+			name = inv.containers[id].Name
+			inv.mu.RUnlock()
+		}
+		return &InspectError{ID: id, Name: name, Err: err}
+	}
+
+	if !inv.client.filter.MatchInfo(info) {
+		inv.remove(id)
+		return nil
+	}
+
+	inv.mu.Lock()
+	inv.containers[id] = info
+	inv.mu.Unlock()
+
+	return nil
+}
+
+// remove deletes a container from the inventory
+func (inv *Inventory) remove(id string) {
+	inv.mu.Lock()
+	delete(inv.containers, id)
+	inv.mu.Unlock()
+}
@@ -0,0 +1,158 @@
+package docker
+
+import (
+	"context"
+	"math"
+	"net/netip"
+	"sort"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/network"
+)
+
+// NetworkInfo holds network information
+type NetworkInfo struct {
+	ID         string // short network ID, names are not unique on older daemons
+	Name       string
+	Driver     string
+	Scope      string
+	Containers int
+	Subnets    []SubnetUsage
+}
+
+// SubnetUsage holds the address usage of an IPAM subnet
+type SubnetUsage struct {
+	Subnet    string
+	Size      float64 // assignable addresses, float64 because IPv6 subnets overflow int64
+	Allocated int     // addresses assigned to containers
+}
+
+// ContainerNetwork holds the address of a container on one network
+type ContainerNetwork struct {
+	Name      string
+	IPAddress string
+}
+
+// ListNetworks returns all networks with their attached containers and IPAM usage.
+// NetworkList does not include endpoints, so every network is inspected; networks
+// removed in between are skipped, any other inspect error fails the list.
+func (c *Client) ListNetworks(ctx context.Context) ([]NetworkInfo, error) {
+	networks, err := c.cli.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]NetworkInfo, 0, len(networks))
+	for _, summary := range networks {
+		inspect, err := c.cli.NetworkInspect(ctx, summary.ID, network.InspectOptions{})
+		if err != nil {
+			// Removed between the list and the inspect call
+			if cerrdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, networkInfo(inspect))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// networkInfo converts an inspected network, counting the addresses in use per subnet
+func networkInfo(inspect network.Inspect) NetworkInfo {
+	info := NetworkInfo{
+		ID:         shortID(inspect.ID),
+		Name:       inspect.Name,
+		Driver:     inspect.Driver,
+		Scope:      inspect.Scope,
+		Containers: len(inspect.Containers),
+	}
+
+	var addrs []netip.Addr
+	for _, endpoint := range inspect.Containers {
+		for _, cidr := range []string{endpoint.IPv4Address, endpoint.IPv6Address} {
+			if prefix, err := netip.ParsePrefix(cidr); err == nil {
+				addrs = append(addrs, prefix.Addr())
+			}
+		}
+	}
+
+	for _, cfg := range inspect.IPAM.Config {
+		subnet, err := netip.ParsePrefix(cfg.Subnet)
+		if err != nil {
+			continue
+		}
+		subnet = subnet.Masked()
+
+		// Containers are only assigned addresses from the ip-range when one is set
+		pool := subnet
+		if ipRange, err := netip.ParsePrefix(cfg.IPRange); err == nil && subnet.Overlaps(ipRange) {
+			pool = ipRange.Masked()
+		}
+
+		reserved := reservedAddrs(subnet)
+		if gateway, err := netip.ParseAddr(cfg.Gateway); err == nil {
+			reserved = append(reserved, gateway)
+		}
+		for _, aux := range cfg.AuxAddress {
+			if addr, err := netip.ParseAddr(aux); err == nil {
+				reserved = append(reserved, addr)
+			}
+		}
+
+		usage := SubnetUsage{Subnet: subnet.String(), Size: poolSize(pool)}
+		seen := make(map[netip.Addr]bool)
+		for _, addr := range reserved {
+			if pool.Contains(addr) && !seen[addr] {
+				seen[addr] = true
+				usage.Size--
+			}
+		}
+		for _, addr := range addrs {
+			if pool.Contains(addr) {
+				usage.Allocated++
+			}
+		}
+		if usage.Size < 0 {
+			usage.Size = 0
+		}
+		info.Subnets = append(info.Subnets, usage)
+	}
+
+	return info
+}
+
+// poolSize returns the number of addresses in an address pool
+func poolSize(pool netip.Prefix) float64 {
+	return math.Exp2(float64(pool.Addr().BitLen() - pool.Bits()))
+}
+
+// reservedAddrs returns the addresses of a subnet that IPAM never hands out: the
+// network address, and the broadcast address for IPv4 subnets larger than a /31.
+func reservedAddrs(subnet netip.Prefix) []netip.Addr {
+	hostBits := subnet.Addr().BitLen() - subnet.Bits()
+	if hostBits < 2 {
+		return nil
+	}
+	reserved := []netip.Addr{subnet.Addr()}
+	if subnet.Addr().Is4() {
+		broadcast := subnet.Addr().As4()
+		for i := range broadcast {
+			bits := subnet.Bits() - i*8
+			switch {
+			case bits <= 0:
+				broadcast[i] = 0xff
+			case bits < 8:
+				broadcast[i] |= 0xff >> bits
+			}
+		}
+		reserved = append(reserved, netip.AddrFrom4(broadcast))
+	}
+	return reserved
+}
@@ -0,0 +1,166 @@
+package docker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// TaskStates lists the swarm task states in lifecycle order
+var TaskStates = []string{
+	string(swarm.TaskStateNew),
+	string(swarm.TaskStateAllocated),
+	string(swarm.TaskStatePending),
+	string(swarm.TaskStateAssigned),
+	string(swarm.TaskStateAccepted),
+	string(swarm.TaskStatePreparing),
+	string(swarm.TaskStateReady),
+	string(swarm.TaskStateStarting),
+	string(swarm.TaskStateRunning),
+	string(swarm.TaskStateComplete),
+	string(swarm.TaskStateShutdown),
+	string(swarm.TaskStateFailed),
+	string(swarm.TaskStateRejected),
+	string(swarm.TaskStateRemove),
+	string(swarm.TaskStateOrphaned),
+}
+
+// ServiceInfo holds swarm service information
+type ServiceInfo struct {
+	ID      string
+	Name    string
+	Mode    string // replicated, global, replicated-job or global-job
+	Desired uint64
+	Running uint64
+	Tasks   map[string]int // task count by state
+}
+
+// NodeInfo holds swarm node information
+type NodeInfo struct {
+	ID           string
+	Hostname     string
+	Role         string
+	Availability string
+	Status       string
+	Manager      bool
+	Leader       bool
+	Reachable    bool // only set for managers
+}
+
+// SwarmManager reports whether the daemon is a manager of an active swarm.
+// Service, task and node lists are only served by managers.
+func (c *Client) SwarmManager(ctx context.Context) (bool, error) {
+	info, err := c.cli.Info(ctx)
+	if err != nil {
+		return false, err
+	}
+	return info.Swarm.LocalNodeState == swarm.LocalNodeStateActive && info.Swarm.ControlAvailable, nil
+}
+
+// GetServices returns the swarm services with their replica and task counts
+func (c *Client) GetServices(ctx context.Context) ([]ServiceInfo, error) {
+	services, err := c.cli.ServiceList(ctx, swarm.ServiceListOptions{Status: true})
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.cli.TaskList(ctx, swarm.TaskListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	tasksByService := make(map[string]map[string]int, len(services))
+	desiredByService := make(map[string]uint64, len(services))
+	for _, task := range tasks {
+		if tasksByService[task.ServiceID] == nil {
+			tasksByService[task.ServiceID] = make(map[string]int)
+		}
+		tasksByService[task.ServiceID][string(task.Status.State)]++
+		if task.DesiredState == swarm.TaskStateRunning {
+			desiredByService[task.ServiceID]++
+		}
+	}
+
+	result := make([]ServiceInfo, 0, len(services))
+	for _, svc := range services {
+		info := ServiceInfo{
+			ID:    shortID(svc.ID),
+			Name:  svc.Spec.Name,
+			Mode:  serviceMode(svc.Spec.Mode),
+			Tasks: tasksByService[svc.ID],
+		}
+		if info.Tasks == nil {
+			info.Tasks = make(map[string]int)
+		}
+
+		// ServiceStatus requires API 1.41; older daemons only report the spec
+		if svc.ServiceStatus != nil {
+			info.Desired = svc.ServiceStatus.DesiredTasks
+			info.Running = svc.ServiceStatus.RunningTasks
+		} else {
+			info.Desired = desiredByService[svc.ID]
+			if svc.Spec.Mode.Replicated != nil && svc.Spec.Mode.Replicated.Replicas != nil {
+				info.Desired = *svc.Spec.Mode.Replicated.Replicas
+			}
+			info.Running = uint64(info.Tasks[string(swarm.TaskStateRunning)])
+		}
+
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// GetNodes returns the swarm nodes
+func (c *Client) GetNodes(ctx context.Context) ([]NodeInfo, error) {
+	nodes, err := c.cli.NodeList(ctx, swarm.NodeListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		info := NodeInfo{
+			ID:           shortID(node.ID),
+			Hostname:     node.Description.Hostname,
+			Role:         string(node.Spec.Role),
+			Availability: string(node.Spec.Availability),
+			Status:       string(node.Status.State),
+		}
+		if node.ManagerStatus != nil {
+			info.Manager = true
+			info.Leader = node.ManagerStatus.Leader
+			info.Reachable = node.ManagerStatus.Reachability == swarm.ReachabilityReachable
+		}
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Hostname < result[j].Hostname
+	})
+	return result, nil
+}
+
+// serviceMode returns the name of a service mode
+func serviceMode(mode swarm.ServiceMode) string {
+	switch {
+	case mode.Global != nil:
+		return "global"
+	case mode.ReplicatedJob != nil:
+		return "replicated-job"
+	case mode.GlobalJob != nil:
+		return "global-job"
+	}
+	return "replicated"
+}
+
+// shortID truncates a swarm object ID to 12 characters like the Docker CLI
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// VolumeInfo holds volume information
+type VolumeInfo struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Scope      string
+}
+
+// VolumeUsage holds the disk usage of a volume
+type VolumeUsage struct {
+	Name     string
+	Size     int64 // -1 when the driver cannot report it
+	RefCount int64 // containers referencing the volume
+}
+
+// ListVolumes returns all volumes, or only those not referenced by any container when dangling is set
+func (c *Client) ListVolumes(ctx context.Context, dangling bool) ([]VolumeInfo, error) {
+	opts := volume.ListOptions{}
+	if dangling {
+		opts.Filters = filters.NewArgs(filters.Arg("dangling", "true"))
+	}
+
+	resp, err := c.cli.VolumeList(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]VolumeInfo, 0, len(resp.Volumes))
+	for _, vol := range resp.Volumes {
+		result = append(result, VolumeInfo{
+			Name:       vol.Name,
+			Driver:     vol.Driver,
+			Mountpoint: vol.Mountpoint,
+			Scope:      vol.Scope,
+		})
+	}
+
+	return result, nil
+}